@@ -3,6 +3,12 @@ https://github.com/openatx/uiautomator2#app-management
 */
 package uiautomator
 
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
 /*
 Install an app
 TODO: api "/install" not work
@@ -39,3 +45,103 @@ func (ua *UIAutomator) AppStop(packageName string) error {
 
 	return err
 }
+
+const (
+	_ACTION_VIEW      = "android.intent.action.VIEW"
+	_RESOLVER_PACKAGE = "com.android.intentresolver"
+)
+
+var (
+	_AM_START_ACTIVITY_REGEXP = regexp.MustCompile(`(?m)^Activity:\s+(` + _COMPONENT + `)`)
+	_AM_START_ERROR_REGEXP    = regexp.MustCompile(`(?m)^Error(?: type \d+)?:\s*(.+)$`)
+	_CHOOSER_ACTIVITIES       = []string{
+		"com.android.internal.app.ResolverActivity",
+		"com.android.internal.app.ChooserActivity",
+	}
+)
+
+type (
+	OpenURLOptions struct {
+		Package string  // Optional target package
+		Timeout float32 // Wait for the activity(second), default is 10s
+	}
+
+	OpenURLResult struct {
+		App     *AppInfo // The activity handled the link
+		Chooser bool     // The link is waiting for the chooser dialog
+	}
+)
+
+/*
+Open an url via the ACTION_VIEW intent, and wait the resolved activity come to the foreground
+*/
+func (ua *UIAutomator) OpenURL(url string, options *OpenURLOptions) (*OpenURLResult, error) {
+	if options == nil {
+		options = &OpenURLOptions{}
+	}
+
+	if options.Timeout <= 0 {
+		options.Timeout = 10
+	}
+
+	command := []string{"am", "start", "-W", "-a", _ACTION_VIEW, "-d", url}
+	if options.Package != "" {
+		command = append(command, "-p", options.Package)
+	}
+
+	output, err := ua.shellArgs(command, int(options.Timeout)+5)
+	if err != nil {
+		return nil, err
+	}
+
+	if matched := _AM_START_ERROR_REGEXP.FindStringSubmatch(output); matched != nil {
+		return nil, fmt.Errorf("OpenURL: %s", matched[1])
+	}
+
+	// The package expected in the foreground
+	expected := options.Package
+	if matched := _AM_START_ACTIVITY_REGEXP.FindStringSubmatch(output); matched != nil {
+		if resolved := parseComponent(matched[1]); resolved != nil {
+			expected = resolved.Package
+		}
+	}
+
+	deadline := time.Now().Add(time.Duration(options.Timeout*1000) * time.Millisecond)
+
+	for {
+		info, err := ua.GetCurrentApp()
+		if err == nil {
+			if isChooser(info) {
+				return &OpenURLResult{App: info, Chooser: true}, nil
+			}
+
+			if expected == "" || info.Package == expected {
+				return &OpenURLResult{App: info}, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("OpenURL: %q not in the foreground after %vs", expected, options.Timeout)
+		}
+
+		time.Sleep(time.Duration(500) * time.Millisecond)
+	}
+}
+
+/*
+Check the app is the intent chooser(resolver) dialog
+*/
+func isChooser(info *AppInfo) bool {
+	if info.Package == _RESOLVER_PACKAGE {
+		return true
+	}
+
+	activity := fullActivity(info)
+	for _, chooser := range _CHOOSER_ACTIVITIES {
+		if activity == chooser {
+			return true
+		}
+	}
+
+	return false
+}
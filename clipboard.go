@@ -0,0 +1,91 @@
+/**
+https://github.com/openatx/uiautomator2#clipboard
+*/
+package uiautomator
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+const (
+	// Android 10+ restricts the clipboard access in the background
+	_SDK_CLIPBOARD_RESTRICTED = 29
+)
+
+var _BROADCAST_DATA_REGEXP = regexp.MustCompile(`(?s)data="(.*)"`)
+
+/*
+Check the clipboard has to be accessed via the input method
+*/
+func (ua *UIAutomator) clipboardRestricted() bool {
+	info, err := ua.GetDeviceInfo()
+	return err == nil && info.SdkInt >= _SDK_CLIPBOARD_RESTRICTED
+}
+
+/*
+Get the clipboard text
+*/
+func (ua *UIAutomator) GetClipboard() (string, error) {
+	if !ua.clipboardRestricted() {
+		var text string
+		transform := func(payload interface{}, response *http.Response) error {
+			text, _ = payload.(string)
+			return nil
+		}
+
+		if err := ua.post(
+			&RPCOptions{
+				Method: "getClipboard",
+				Params: []interface{}{},
+			},
+			nil,
+			transform,
+		); err == nil {
+			return text, nil
+		}
+	}
+
+	// Fallback to FastInputIME, the focused input method can access the clipboard
+	if err := ua.waitFastinputIME(); err != nil {
+		return "", err
+	}
+
+	output, err := ua.Shell([]string{"am", "broadcast", "-a", "ADB_GET_CLIPBOARD"}, 5)
+	if err != nil {
+		return "", err
+	}
+
+	matched := _BROADCAST_DATA_REGEXP.FindStringSubmatch(output)
+	if matched == nil {
+		return "", fmt.Errorf("GetClipboard: unexpected output %q", output)
+	}
+
+	return matched[1], nil
+}
+
+/*
+Set the clipboard text
+*/
+func (ua *UIAutomator) SetClipboard(text string, label string) error {
+	if !ua.clipboardRestricted() {
+		if err := ua.post(
+			&RPCOptions{
+				Method: "setClipboard",
+				Params: []interface{}{label, text},
+			},
+			nil,
+			nil,
+		); err == nil {
+			return nil
+		}
+	}
+
+	if err := ua.waitFastinputIME(); err != nil {
+		return err
+	}
+
+	_, err := ua.shellArgs([]string{"am", "broadcast", "-a", "ADB_SET_CLIPBOARD", "--es", "text", text, "--es", "label", label}, 5)
+	return err
+}
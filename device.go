@@ -6,8 +6,11 @@ package uiautomator
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
+	"strings"
 )
 
 type (
@@ -32,6 +35,8 @@ type (
 	AppInfo struct {
 		Activity string `json:"activity"`
 		Package  string `json:"package"`
+		Pid      int    `json:"pid"`
+		Resumed  bool   `json:"resumed"`
 	}
 )
 
@@ -41,14 +46,20 @@ Get basic information
 func (ua *UIAutomator) GetDeviceInfo() (*DeviceInfo, error) {
 	result := &DeviceInfo{}
 
-	return result, ua.post(
+	if err := ua.post(
 		&RPCOptions{
 			Method: "deviceInfo",
 			Params: []interface{}{},
 		},
 		result,
 		nil,
-	)
+	); err != nil {
+		return result, err
+	}
+
+	// Keep the cached window size in the current rotation
+	ua.cacheWindowSize(result)
+	return result, nil
 }
 
 /*
@@ -76,29 +87,162 @@ func (ua *UIAutomator) GetWindowSize() (*WindowSize, error) {
 }
 
 /*
-Get current app info
+Get current app info, tries the window focus, the top activity and the
+resumed activity in order, and falls back to the uiautomator current package
 */
-func (ua *UIAutomator) GetCurrentApp() (info *AppInfo, err error) {
-	output, err := ua.Shell([]string{"dumpsys", "window", "windows"}, 10)
-	if err != nil {
-		return
+func (ua *UIAutomator) GetCurrentApp() (*AppInfo, error) {
+	var info *AppInfo
+
+	sources := []struct {
+		command []string
+		parse   func(string) *AppInfo
+	}{
+		{[]string{"dumpsys", "window"}, parseWindowFocus},
+		{[]string{"dumpsys", "activity", "top"}, parseActivityTop},
+		{[]string{"dumpsys", "activity", "activities"}, parseResumedActivity},
+	}
+
+	for _, source := range sources {
+		output, err := ua.Shell(source.command, 10)
+		if err != nil {
+			continue
+		}
+
+		if info = source.parse(output); info != nil {
+			break
+		}
 	}
 
-	r := regexp.MustCompile(`mCurrentFocus=Window{.*\s+(?P<package>[^\s]+)/(?P<activity>[^\s]+)\}`)
-	matched := r.FindStringSubmatch(output)
-	res := make(map[string]string)
+	if info == nil {
+		deviceInfo, err := ua.GetDeviceInfo()
+		if err != nil {
+			return nil, err
+		}
 
-	for i, name := range r.SubexpNames() {
-		if i != 0 && len(name) > 0 {
-			res[name] = matched[i]
+		if deviceInfo.CurrentPackageName == "" {
+			return nil, fmt.Errorf("GetCurrentApp: no app in the foreground")
 		}
+
+		info = &AppInfo{Package: deviceInfo.CurrentPackageName}
+	}
+
+	if info.Pid == 0 {
+		if output, err := ua.Shell([]string{"pidof", info.Package}, 5); err == nil {
+			fields := strings.Fields(output)
+			if len(fields) > 0 {
+				info.Pid, _ = strconv.Atoi(fields[0])
+			}
+		}
+	}
+
+	if !info.Resumed && info.Activity != "" {
+		if output, err := ua.Shell([]string{"dumpsys", "activity", "activities"}, 10); err == nil {
+			info.Resumed = isResumed(info, output)
+		}
+	}
+
+	return info, nil
+}
+
+var (
+	_WINDOW_FOCUS_REGEXP    = regexp.MustCompile(`(?:mCurrentFocus|mFocusedApp)=\S+\{[^}]*?\s(` + _COMPONENT + `)[\s}]`)
+	_ACTIVITY_TOP_REGEXP    = regexp.MustCompile(`ACTIVITY\s+(` + _COMPONENT + `)\s+\w+\s+pid=(\d+|\(not running\))`)
+	_ACTIVITY_RESUME_REGEXP = regexp.MustCompile(`(?:mResumedActivity|ResumedActivity|topResumedActivity)[:=]\s*ActivityRecord\{[^}]*?\s(` + _COMPONENT + `)[\s}]`)
+	_ACTIVITY_STATE_REGEXP  = regexp.MustCompile(`mResumed=(true|false)`)
+)
+
+const _COMPONENT = `[\w.]+/[\w.$]+`
+
+/*
+Check if the app is the resumed activity of "dumpsys activity activities"
+*/
+func isResumed(info *AppInfo, output string) bool {
+	resumed := parseResumedActivity(output)
+	if resumed == nil {
+		return false
+	}
+
+	return resumed.Package == info.Package && fullActivity(resumed) == fullActivity(info)
+}
+
+/*
+Expand the short activity like ".Settings" with the package
+*/
+func fullActivity(info *AppInfo) string {
+	if strings.HasPrefix(info.Activity, ".") {
+		return info.Package + info.Activity
+	}
+
+	return info.Activity
+}
+
+/*
+Split a component like "com.android.settings/.Settings"
+*/
+func parseComponent(component string) *AppInfo {
+	parts := strings.SplitN(component, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+
+	return &AppInfo{
+		Package:  parts[0],
+		Activity: parts[1],
 	}
+}
 
-	info = &AppInfo{
-		Package:  res["package"],
-		Activity: res["activity"],
+/*
+Parse the focused window from "dumpsys window"
+*/
+func parseWindowFocus(output string) *AppInfo {
+	matched := _WINDOW_FOCUS_REGEXP.FindStringSubmatch(output)
+	if matched == nil {
+		return nil
 	}
-	return
+
+	return parseComponent(matched[1])
+}
+
+/*
+Parse the last (topmost) activity from "dumpsys activity top"
+*/
+func parseActivityTop(output string) *AppInfo {
+	indexes := _ACTIVITY_TOP_REGEXP.FindAllStringSubmatchIndex(output, -1)
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	last := indexes[len(indexes)-1]
+	info := parseComponent(output[last[2]:last[3]])
+	if info == nil {
+		return nil
+	}
+
+	info.Pid, _ = strconv.Atoi(output[last[4]:last[5]])
+
+	// Only look at the state of the topmost activity
+	if state := _ACTIVITY_STATE_REGEXP.FindStringSubmatch(output[last[1]:]); state != nil {
+		info.Resumed = state[1] == "true"
+	}
+
+	return info
+}
+
+/*
+Parse the resumed activity from "dumpsys activity activities"
+*/
+func parseResumedActivity(output string) *AppInfo {
+	matched := _ACTIVITY_RESUME_REGEXP.FindStringSubmatch(output)
+	if matched == nil {
+		return nil
+	}
+
+	info := parseComponent(matched[1])
+	if info != nil {
+		info.Resumed = true
+	}
+
+	return info
 }
 
 /*
@@ -0,0 +1,241 @@
+package uiautomator
+
+import (
+	"testing"
+)
+
+const (
+	_WINDOW_SDK23 = `WINDOW MANAGER WINDOWS (dumpsys window windows)
+  Window #5 Window{3f2b9c1 u0 com.android.settings/com.android.settings.Settings}:
+    mDisplayId=0 stackId=1 mSession=Session{1a2b3c4 4521:1000} mClient=android.os.BinderProxy@5d6e7f8
+    mOwnerUid=1000 mShowToOwnerOnly=true package=com.android.settings appop=NONE
+  Window #4 Window{7a8b9c0 u0 com.android.launcher3/com.android.launcher3.Launcher}:
+    mDisplayId=0 stackId=0 mSession=Session{2b3c4d5 1890:u0a22} mClient=android.os.BinderProxy@6e7f8a9
+
+  mCurrentFocus=Window{3f2b9c1 u0 com.android.settings/com.android.settings.Settings}
+  mFocusedApp=AppWindowToken{1c5e8a6 token=Token{2c1f0e1 ActivityRecord{3a9d548 u0 com.android.settings/.Settings t12}}}
+  mInputMethodTarget=Window{3f2b9c1 u0 com.android.settings/com.android.settings.Settings}
+`
+
+	_WINDOW_SDK28 = `WINDOW MANAGER WINDOWS (dumpsys window windows)
+  Window #9 Window{8a1c2d3 u0 com.google.android.apps.nexuslauncher/com.google.android.apps.nexuslauncher.NexusLauncherActivity}:
+    mDisplayId=0 stackId=0 mSession=Session{e3f4a5b 2211:u0a10043} mClient=android.os.BinderProxy@c6d7e8f
+
+  mGlobalConfiguration={1.0 310mcc260mnc [en_US] ldltr sw411dp w411dp h659dp 420dpi nrml port finger -keyb/v/h -nav/h winConfig={ mBounds=Rect(0, 0 - 0, 0) mAppBounds=Rect(0, 0 - 1080, 1794) mWindowingMode=fullscreen mActivityType=undefined} s.6}
+  mHasPermanentDpad=false
+  mTopFocusedDisplayId=0
+  mCurrentFocus=Window{8a1c2d3 u0 com.google.android.apps.nexuslauncher/com.google.android.apps.nexuslauncher.NexusLauncherActivity}
+  mFocusedApp=AppWindowToken{5e7f6a1 token=Token{d0b1c47 ActivityRecord{1234abc u0 com.google.android.apps.nexuslauncher/.NexusLauncherActivity t2}}}
+`
+
+	_WINDOW_SDK29_POPUP = `WINDOW MANAGER WINDOWS (dumpsys window windows)
+  Window #11 Window{b1c2d3e u0 PopupWindow:9f8e7d6}:
+    mDisplayId=0 stackId=27 mSession=Session{a9b8c7d 6012:u0a10151} mClient=android.os.BinderProxy@e1f2a3b
+
+  mTopFocusedDisplayId=0
+  mCurrentFocus=Window{b1c2d3e u0 PopupWindow:9f8e7d6}
+  mFocusedApp=AppWindowToken{4c5d6e7 token=Token{8f9a0b1 ActivityRecord{c2d3e4f u0 com.google.android.gm/.ConversationListActivityGmail t27}}}
+`
+
+	_WINDOW_SDK31 = `WINDOW MANAGER WINDOWS (dumpsys window windows)
+  Window #13 Window{f1e2d3c u0 com.android.chrome/com.google.android.apps.chrome.Main}:
+    mDisplayId=0 rootTaskId=45 mSession=Session{7d8e9fa 8123:u0a10167} mClient=android.os.BinderProxy@1b2c3d4
+    mOwnerUid=10167 showForAllUsers=false package=com.android.chrome appop=NONE
+
+  mGlobalConfiguration={1.0 310mcc260mnc [en_US] ldltr sw411dp w411dp h842dp 420dpi nrml long port finger -keyb/v/h -nav/h winConfig={ mBounds=Rect(0, 0 - 1080, 2400) mAppBounds=Rect(0, 0 - 1080, 2400) mMaxBounds=Rect(0, 0 - 1080, 2400) mWindowingMode=fullscreen mDisplayWindowingMode=fullscreen mActivityType=undefined mAlwaysOnTop=undefined mRotation=ROTATION_0} s.12 fontWeightAdjustment=0}
+  mHasPermanentDpad=false
+  mTopFocusedDisplayId=0
+  mCurrentFocus=Window{f1e2d3c u0 com.android.chrome/com.google.android.apps.chrome.Main}
+  mFocusedApp=ActivityRecord{9b8a7f6 u0 com.android.chrome/com.google.android.apps.chrome.Main t45}
+`
+
+	_WINDOW_SDK33 = `WINDOW MANAGER WINDOWS (dumpsys window windows)
+  Window #14 Window{2a3b4c5 u0 com.example.app/com.example.app.ui.MainActivity$Embedded}:
+    mDisplayId=0 rootTaskId=61 mSession=Session{3b4c5d6 9876:u0a10201} mClient=android.os.BinderProxy@4c5d6e7
+
+  mTopFocusedDisplayId=0
+  mCurrentFocus=Window{2a3b4c5 u0 com.example.app/com.example.app.ui.MainActivity$Embedded}
+  mFocusedApp=ActivityRecord{5d6e7f8 u0 com.example.app/.ui.MainActivity$Embedded t61}
+`
+
+	_WINDOW_SDK33_LOCKED = `WINDOW MANAGER WINDOWS (dumpsys window windows)
+  Window #15 Window{4d5c6b7 u0 NotificationShade}:
+    mDisplayId=0 rootTaskId=1 mSession=Session{6e7f8a9 1543:u0a10082} mClient=android.os.BinderProxy@7f8a9b0
+    mOwnerUid=10082 showForAllUsers=true package=com.android.systemui appop=NONE
+  Window #8 Window{9a0b1c2 u0 com.google.android.apps.nexuslauncher/com.google.android.apps.nexuslauncher.NexusLauncherActivity}:
+    mDisplayId=0 rootTaskId=1 mSession=Session{e3f4a5b 2211:u0a10043} mClient=android.os.BinderProxy@c6d7e8f
+
+  mTopFocusedDisplayId=0
+  mCurrentFocus=Window{4d5c6b7 u0 NotificationShade}
+  mFocusedApp=null
+`
+
+	_ACTIVITY_TOP_SDK23 = `TASK com.android.launcher3 id=1
+  ACTIVITY com.android.launcher3/.Launcher 7a8b9c0 pid=1890
+    Local Activity 1d2e3f4 State:
+      mResumed=false mStopped=true mFinished=false
+TASK com.android.settings id=12
+  ACTIVITY com.android.settings/.Settings 3a9d548 pid=4521
+    Local Activity 8c1d2e5 State:
+      mResumed=true mStopped=false mFinished=false
+      mChangingConfigurations=false
+`
+
+	_ACTIVITY_TOP_SDK28_STOPPED = `TASK com.google.android.apps.nexuslauncher id=2 userId=0
+  ACTIVITY com.google.android.apps.nexuslauncher/.NexusLauncherActivity 1234abc pid=2211
+    Local Activity 5f6a7b8 State:
+      mResumed=false mStopped=true mFinished=false
+`
+
+	_ACTIVITY_TOP_SDK31 = `TASK 0x1 id=1 userId=0
+  ACTIVITY com.google.android.apps.nexuslauncher/.NexusLauncherActivity 1234abc pid=2211
+    Local Activity 5f6a7b8 State:
+      mResumed=false mStopped=true mFinished=false
+TASK 0x2d id=45 userId=0
+  ACTIVITY com.android.chrome/com.google.android.apps.chrome.Main 9b8a7f6 pid=8123
+    Local Activity 0c1d2e3 State:
+      mResumed=true mStopped=false mFinished=false
+      mIsInMultiWindowMode=false mIsInPictureInPictureMode=false
+`
+
+	_ACTIVITY_TOP_SDK33_DEAD = `TASK 0x3d id=61 userId=0
+  ACTIVITY com.example.app/.ui.MainActivity$Embedded 5d6e7f8 pid=(not running)
+`
+
+	_ACTIVITIES_SDK23 = `ACTIVITY MANAGER ACTIVITIES (dumpsys activity activities)
+Display #0 (activities from top to bottom):
+  Stack #1:
+    Task id #12
+      * TaskRecord{6b7c8d9 #12 A=com.android.settings U=0 sz=1}
+        Hist #0: ActivityRecord{3a9d548 u0 com.android.settings/.Settings t12}
+    mResumedActivity: ActivityRecord{3a9d548 u0 com.android.settings/.Settings t12}
+  mFocusedActivity: ActivityRecord{3a9d548 u0 com.android.settings/.Settings t12}
+`
+
+	_ACTIVITIES_SDK29 = `ACTIVITY MANAGER ACTIVITIES (dumpsys activity activities)
+Display #0 (activities from top to bottom):
+  Stack #27: type=standard mode=fullscreen
+    mResumedActivity: ActivityRecord{c2d3e4f u0 com.google.android.gm/.ConversationListActivityGmail t27}
+    mLastPausedActivity: ActivityRecord{1234abc u0 com.google.android.apps.nexuslauncher/.NexusLauncherActivity t2}
+  ResumedActivity: ActivityRecord{c2d3e4f u0 com.google.android.gm/.ConversationListActivityGmail t27}
+`
+
+	_ACTIVITIES_SDK33 = `ACTIVITY MANAGER ACTIVITIES (dumpsys activity activities)
+Display #0 (activities from top to bottom):
+  * Task{8e9f0a1 #45 type=standard A=10167:com.android.chrome U=0 visible=true mode=fullscreen translucent=false sz=1}
+    topResumedActivity=ActivityRecord{9b8a7f6 u0 com.android.chrome/com.google.android.apps.chrome.Main t45}
+    * Hist  #0: ActivityRecord{9b8a7f6 u0 com.android.chrome/com.google.android.apps.chrome.Main t45}
+  Resumed activities in task display areas (from top to bottom):
+    ResumedActivity: ActivityRecord{9b8a7f6 u0 com.android.chrome/com.google.android.apps.chrome.Main t45}
+`
+
+	_ACTIVITIES_SDK33_LOCKED = `ACTIVITY MANAGER ACTIVITIES (dumpsys activity activities)
+Display #0 (activities from top to bottom):
+  * Task{3c4d5e6 #1 type=home ?? U=0 visible=false mode=fullscreen translucent=false sz=1}
+    mLastPausedActivity: ActivityRecord{9a0b1c2 u0 com.google.android.apps.nexuslauncher/.NexusLauncherActivity t1}
+    * Hist  #0: ActivityRecord{9a0b1c2 u0 com.google.android.apps.nexuslauncher/.NexusLauncherActivity t1}
+  Resumed activities in task display areas (from top to bottom):
+`
+)
+
+func TestParseWindowFocus(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		want   *AppInfo
+	}{
+		{"sdk23", _WINDOW_SDK23, &AppInfo{Package: "com.android.settings", Activity: "com.android.settings.Settings"}},
+		{"sdk28", _WINDOW_SDK28, &AppInfo{Package: "com.google.android.apps.nexuslauncher", Activity: "com.google.android.apps.nexuslauncher.NexusLauncherActivity"}},
+		{"sdk29 popup", _WINDOW_SDK29_POPUP, &AppInfo{Package: "com.google.android.gm", Activity: ".ConversationListActivityGmail"}},
+		{"sdk31", _WINDOW_SDK31, &AppInfo{Package: "com.android.chrome", Activity: "com.google.android.apps.chrome.Main"}},
+		{"sdk33", _WINDOW_SDK33, &AppInfo{Package: "com.example.app", Activity: "com.example.app.ui.MainActivity$Embedded"}},
+		{"sdk33 locked", _WINDOW_SDK33_LOCKED, nil},
+		{"empty", "", nil},
+	}
+
+	for _, c := range cases {
+		assertAppInfo(t, c.name, parseWindowFocus(c.output), c.want)
+	}
+}
+
+func TestParseActivityTop(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		want   *AppInfo
+	}{
+		{"sdk23", _ACTIVITY_TOP_SDK23, &AppInfo{Package: "com.android.settings", Activity: ".Settings", Pid: 4521, Resumed: true}},
+		{"sdk28 stopped", _ACTIVITY_TOP_SDK28_STOPPED, &AppInfo{Package: "com.google.android.apps.nexuslauncher", Activity: ".NexusLauncherActivity", Pid: 2211}},
+		{"sdk31", _ACTIVITY_TOP_SDK31, &AppInfo{Package: "com.android.chrome", Activity: "com.google.android.apps.chrome.Main", Pid: 8123, Resumed: true}},
+		{"sdk33 not running", _ACTIVITY_TOP_SDK33_DEAD, &AppInfo{Package: "com.example.app", Activity: ".ui.MainActivity$Embedded"}},
+		{"empty", "", nil},
+	}
+
+	for _, c := range cases {
+		assertAppInfo(t, c.name, parseActivityTop(c.output), c.want)
+	}
+}
+
+func TestParseResumedActivity(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		want   *AppInfo
+	}{
+		{"sdk23", _ACTIVITIES_SDK23, &AppInfo{Package: "com.android.settings", Activity: ".Settings", Resumed: true}},
+		{"sdk29", _ACTIVITIES_SDK29, &AppInfo{Package: "com.google.android.gm", Activity: ".ConversationListActivityGmail", Resumed: true}},
+		{"sdk33", _ACTIVITIES_SDK33, &AppInfo{Package: "com.android.chrome", Activity: "com.google.android.apps.chrome.Main", Resumed: true}},
+		{"sdk33 locked", _ACTIVITIES_SDK33_LOCKED, nil},
+	}
+
+	for _, c := range cases {
+		assertAppInfo(t, c.name, parseResumedActivity(c.output), c.want)
+	}
+}
+
+func assertAppInfo(t *testing.T, name string, got, want *AppInfo) {
+	t.Helper()
+
+	if want == nil {
+		if got != nil {
+			t.Errorf("%s: expected nil, got %+v", name, *got)
+		}
+		return
+	}
+
+	if got == nil {
+		t.Errorf("%s: expected %+v, got nil", name, *want)
+		return
+	}
+
+	if *got != *want {
+		t.Errorf("%s: expected %+v, got %+v", name, *want, *got)
+	}
+}
+
+func TestIsResumed(t *testing.T) {
+	cases := []struct {
+		name       string
+		window     string
+		activities string
+		want       bool
+	}{
+		{"sdk23 short activity", _WINDOW_SDK23, _ACTIVITIES_SDK23, true},
+		{"sdk29 popup", _WINDOW_SDK29_POPUP, _ACTIVITIES_SDK29, true},
+		{"sdk31 full activity", _WINDOW_SDK31, _ACTIVITIES_SDK33, true},
+		{"sdk28 other app resumed", _WINDOW_SDK28, _ACTIVITIES_SDK29, false},
+		{"sdk33 locked", _WINDOW_SDK33, _ACTIVITIES_SDK33_LOCKED, false},
+	}
+
+	for _, c := range cases {
+		info := parseWindowFocus(c.window)
+		if info == nil {
+			t.Errorf("%s: expected the focused app", c.name)
+			continue
+		}
+
+		if got := isResumed(info, c.activities); got != c.want {
+			t.Errorf("%s: expected resumed %v, got %v", c.name, c.want, got)
+		}
+	}
+}
@@ -6,10 +6,41 @@ https://github.com/openatx/uiautomator2#retrieve-the-device-info
 package uiautomator
 
 import (
-	"reflect"
+	"context"
+	"fmt"
+	"strconv"
 	"time"
 )
 
+type KeepAwake struct {
+	ua       *UIAutomator
+	snapshot *SettingsSnapshot
+	stopped  chan bool
+	done     chan bool
+}
+
+// Keys supported by "pressKey"
+var _PRESS_KEYS = map[string]bool{
+	"home":        true,
+	"back":        true,
+	"left":        true,
+	"right":       true,
+	"up":          true,
+	"down":        true,
+	"center":      true,
+	"menu":        true,
+	"search":      true,
+	"enter":       true,
+	"delete":      true,
+	"del":         true,
+	"recent":      true,
+	"volume_up":   true,
+	"volume_down": true,
+	"volume_mute": true,
+	"camera":      true,
+	"power":       true,
+}
+
 /*
 Trun on the screen
 */
@@ -67,9 +98,107 @@ func (ua *UIAutomator) IsSleep() (res bool, err error) {
 }
 
 /*
-Press key
+Wait the screen status until the context is done
+*/
+func (ua *UIAutomator) waitScreenStatus(ctx context.Context, wakeUpOrSleep bool) error {
+	ticker := time.NewTicker(time.Duration(500) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		ok, err := ua.checkScreenStatus(wakeUpOrSleep)
+		if err == nil && ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return err
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+Wait the screen turned on
+*/
+func (ua *UIAutomator) WaitScreenOn(ctx context.Context) error {
+	return ua.waitScreenStatus(ctx, true)
+}
+
+/*
+Wait the screen turned off
+*/
+func (ua *UIAutomator) WaitScreenOff(ctx context.Context) error {
+	return ua.waitScreenStatus(ctx, false)
+}
+
+/*
+Keep the screen on while plugged in, and wake up the screen periodically
+if interval is not 0. Release to restore the prior setting
+*/
+func (ua *UIAutomator) KeepAwake(interval time.Duration) (*KeepAwake, error) {
+	snapshot, err := ua.SnapshotSettings(&SettingKey{SETTINGS_GLOBAL, "stay_on_while_plugged_in"})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ua.Shell([]string{"svc", "power", "stayon", "true"}, 5); err != nil {
+		return nil, err
+	}
+
+	keepAwake := &KeepAwake{
+		ua:       ua,
+		snapshot: snapshot,
+	}
+
+	if interval > 0 {
+		keepAwake.stopped = make(chan bool)
+		keepAwake.done = make(chan bool)
+
+		go func() {
+			defer close(keepAwake.done)
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-keepAwake.stopped:
+					return
+				case <-ticker.C:
+					ua.WakeUp()
+				}
+			}
+		}()
+	}
+
+	return keepAwake, nil
+}
+
+/*
+Stop keeping awake, restore the prior setting
+*/
+func (keepAwake *KeepAwake) Release() error {
+	if keepAwake.stopped != nil {
+		close(keepAwake.stopped)
+		<-keepAwake.done
+		keepAwake.stopped = nil
+	}
+
+	return keepAwake.snapshot.Restore()
+}
+
+/*
+Press key, e.g. "home", "back" and "volume_up"
 */
 func (ua *UIAutomator) Press(key string) error {
+	if !_PRESS_KEYS[key] {
+		return fmt.Errorf("Press: unknown key %q", key)
+	}
+
 	return ua.post(
 		&RPCOptions{
 			Method: "pressKey",
@@ -81,12 +210,19 @@ func (ua *UIAutomator) Press(key string) error {
 }
 
 /*
-Press key code
+Press key code with the meta state, e.g. KEYCODE_A with META_SHIFT_ON
 */
-func (ua *UIAutomator) PressKeyCode(key int, meta interface{}) error {
-	params := []interface{}{key}
+func (ua *UIAutomator) PressKeyCode(key Key, meta MetaState) error {
+	if !key.Valid() {
+		return fmt.Errorf("PressKeyCode: invalid key %d", key)
+	}
+
+	if !meta.Valid() {
+		return fmt.Errorf("PressKeyCode: invalid meta state 0x%x", int(meta))
+	}
 
-	if reflect.TypeOf(meta).Kind() == reflect.Int {
+	params := []interface{}{key}
+	if meta != 0 {
 		params = append(params, meta)
 	}
 
@@ -100,6 +236,43 @@ func (ua *UIAutomator) PressKeyCode(key int, meta interface{}) error {
 	)
 }
 
+/*
+Press the keys in sequence with the meta state held, 0 is without meta,
+e.g. PressKeys(META_CTRL_ON, KEYCODE_A, KEYCODE_C) selects all and copies
+*/
+func (ua *UIAutomator) PressKeys(meta MetaState, keys ...Key) error {
+	if !meta.Valid() {
+		return fmt.Errorf("PressKeys: invalid meta state 0x%x", int(meta))
+	}
+
+	// Validate all the keys before pressing any
+	for _, key := range keys {
+		if !key.Valid() {
+			return fmt.Errorf("PressKeys: invalid key %d", key)
+		}
+	}
+
+	for _, key := range keys {
+		if err := ua.PressKeyCode(key, meta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+Long press the key, e.g. KEYCODE_POWER
+*/
+func (ua *UIAutomator) LongPressKey(key Key) error {
+	if !key.Valid() {
+		return fmt.Errorf("LongPressKey: invalid key %d", key)
+	}
+
+	_, err := ua.Shell([]string{"input", "keyevent", "--longpress", strconv.Itoa(int(key))}, 10)
+	return err
+}
+
 /*
 Unblock the device
 */
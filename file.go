@@ -0,0 +1,280 @@
+/**
+Push and pull files via atx-agent
+https://github.com/openatx/atx-agent#api
+*/
+package uiautomator
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_FILE_MODE os.FileMode = 0644
+)
+
+type (
+	TransferOptions struct {
+		Verify   bool                                 // Verify the md5 checksum after transferred
+		Progress func(transferred int64, total int64) // Total is -1 if unknown
+		Timeout  time.Duration                        // Timeout of the whole transfer, 0 is unlimited
+	}
+
+	progressReader struct {
+		reader      io.Reader
+		transferred int64
+		total       int64
+		progress    func(int64, int64)
+	}
+)
+
+func (reader *progressReader) Read(p []byte) (int, error) {
+	n, err := reader.reader.Read(p)
+	reader.transferred += int64(n)
+
+	if n > 0 && reader.progress != nil {
+		reader.progress(reader.transferred, reader.total)
+	}
+
+	return n, err
+}
+
+/*
+Push a local file, directory or io.Reader to the device
+*/
+func (ua *UIAutomator) Push(src interface{}, remotePath string, mode os.FileMode, options *TransferOptions) error {
+	if options == nil {
+		options = &TransferOptions{}
+	}
+
+	if mode == 0 {
+		mode = DEFAULT_FILE_MODE
+	}
+
+	switch typed := src.(type) {
+	case string:
+		stat, err := os.Stat(typed)
+		if err != nil {
+			return err
+		}
+
+		if stat.IsDir() {
+			return ua.pushDir(typed, remotePath, mode, options)
+		}
+
+		return ua.pushFile(typed, remotePath, mode, options)
+	case io.Reader:
+		return ua.push(typed, -1, remotePath, mode, options)
+	default:
+		return fmt.Errorf("Push: unsupported source %T", src)
+	}
+}
+
+func (ua *UIAutomator) pushDir(localDir string, remoteDir string, mode os.FileMode, options *TransferOptions) error {
+	return filepath.Walk(localDir, func(localPath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(localDir, localPath)
+		if err != nil {
+			return err
+		}
+
+		return ua.pushFile(localPath, path.Join(remoteDir, filepath.ToSlash(rel)), mode, options)
+	})
+}
+
+func (ua *UIAutomator) pushFile(localPath string, remotePath string, mode os.FileMode, options *TransferOptions) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Upload to the directory with the local file name
+	if strings.HasSuffix(remotePath, "/") {
+		remotePath += filepath.Base(localPath)
+	}
+
+	return ua.push(file, stat.Size(), remotePath, mode, options)
+}
+
+func (ua *UIAutomator) push(reader io.Reader, size int64, remotePath string, mode os.FileMode, options *TransferOptions) error {
+	if remotePath == "" || strings.HasSuffix(remotePath, "/") {
+		return fmt.Errorf("Push: invalid remote path %q", remotePath)
+	}
+
+	var hasher hash.Hash
+	if options.Verify {
+		hasher = md5.New()
+		reader = io.TeeReader(reader, hasher)
+	}
+
+	reader = &progressReader{
+		reader:   reader,
+		total:    size,
+		progress: options.Progress,
+	}
+
+	body, writer := io.Pipe()
+	defer body.Close()
+	form := multipart.NewWriter(writer)
+
+	go func() {
+		err := form.WriteField("mode", fmt.Sprintf("%04o", mode.Perm()))
+		if err == nil {
+			var part io.Writer
+			if part, err = form.CreateFormFile("file", path.Base(remotePath)); err == nil {
+				if _, err = io.Copy(part, reader); err == nil {
+					err = form.Close()
+				}
+			}
+		}
+		writer.CloseWithError(err)
+	}()
+
+	request, err := http.NewRequest(http.MethodPost, ua.fileURL("upload", remotePath), body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", form.FormDataContentType())
+
+	transform := func(response *http.Response) error {
+		var uploaded struct {
+			Target string `json:"target"`
+		}
+		return json.NewDecoder(response.Body).Decode(&uploaded)
+	}
+
+	if err := ua.executeOnce(request, options.Timeout, transform); err != nil {
+		return err
+	}
+
+	if hasher != nil {
+		return ua.verifyChecksum(remotePath, hasher)
+	}
+
+	return nil
+}
+
+/*
+Pull a file from the device to the writer
+*/
+func (ua *UIAutomator) Pull(remotePath string, dst io.Writer, options *TransferOptions) error {
+	if options == nil {
+		options = &TransferOptions{}
+	}
+
+	var hasher hash.Hash
+	if options.Verify {
+		hasher = md5.New()
+		dst = io.MultiWriter(dst, hasher)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, ua.fileURL("raw", remotePath), nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+
+	transform := func(response *http.Response) error {
+		_, err := io.Copy(dst, &progressReader{
+			reader:   response.Body,
+			total:    response.ContentLength,
+			progress: options.Progress,
+		})
+		return err
+	}
+
+	if err := ua.executeOnce(request, options.Timeout, transform); err != nil {
+		return err
+	}
+
+	if hasher != nil {
+		return ua.verifyChecksum(remotePath, hasher)
+	}
+
+	return nil
+}
+
+/*
+Pull all the files under the remote directory to the local directory
+*/
+func (ua *UIAutomator) PullDir(remoteDir string, localDir string, options *TransferOptions) error {
+	output, err := ua.shellArgs([]string{"find", remoteDir, "-type", "f"}, 30)
+	if err != nil {
+		return err
+	}
+
+	for _, remotePath := range strings.Split(output, "\n") {
+		remotePath = strings.TrimSpace(remotePath)
+		if remotePath == "" {
+			continue
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(remotePath, remoteDir), "/")
+		localPath := filepath.Join(localDir, filepath.FromSlash(rel))
+
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return err
+		}
+
+		if err := ua.pullFile(remotePath, localPath, options); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ua *UIAutomator) pullFile(remotePath string, localPath string, options *TransferOptions) error {
+	file, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return ua.Pull(remotePath, file, options)
+}
+
+/*
+Compare the md5 checksum with the remote file
+*/
+func (ua *UIAutomator) verifyChecksum(remotePath string, hasher hash.Hash) error {
+	output, err := ua.shellArgs([]string{"md5sum", remotePath}, 30)
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(output)
+	expected := hex.EncodeToString(hasher.Sum(nil))
+
+	if len(fields) == 0 || fields[0] != expected {
+		return fmt.Errorf("Checksum mismatch: %s, expected %s got %q", remotePath, expected, output)
+	}
+
+	return nil
+}
+
+func (ua *UIAutomator) fileURL(endpoint string, remotePath string) string {
+	escaped := (&url.URL{Path: path.Clean("/" + remotePath)}).EscapedPath()
+	return fmt.Sprintf("http://%s:%d/%s%s", ua.config.Host, ua.config.Port, endpoint, escaped)
+}
@@ -6,63 +6,216 @@ package uiautomator
 
 import (
 	"fmt"
+	"math"
+	"sync"
 	"time"
 )
 
-type Position struct {
-	X float32
-	Y float32
-}
+type (
+	// Legacy position, the value less than 1 is a fraction of the window, otherwise pixels
+	Position struct {
+		X float32
+		Y float32
+	}
+
+	// Position in pixels
+	Point struct {
+		X int
+		Y int
+	}
+
+	// Position in fractions of the window, from 0 to 1
+	RelPoint struct {
+		X float32
+		Y float32
+	}
+
+	// Accepted by the gesture APIs, e.g. *Position, Point and RelPoint
+	Coordinate interface {
+		relative() bool
+		abs(size *WindowSize) (*Position, error)
+	}
+
+	// Window size of the rotation, checked against the device periodically
+	displayCache struct {
+		mutex    sync.Mutex
+		size     *WindowSize
+		rotation int
+		checked  time.Time
+	}
+)
+
+// The cached window size is re-validated after it, the device may be rotated by the app or the sensor
+const _WINDOW_SIZE_TTL = 2 * time.Second
 
 func (pos *Position) String() string {
-	return fmt.Sprintf("%s, %s", pos.X, pos.Y)
+	return fmt.Sprintf("%v, %v", pos.X, pos.Y)
+}
+
+func (pos *Position) relative() bool {
+	return pos != nil && (pos.X < 1 || pos.Y < 1)
+}
+
+func (pos *Position) abs(size *WindowSize) (*Position, error) {
+	if pos == nil || pos.X < 0 || pos.Y < 0 {
+		return nil, fmt.Errorf("Invalid position %v", pos)
+	}
+
+	abs := &Position{X: pos.X, Y: pos.Y}
+
+	if pos.X < 1 {
+		abs.X = float32(size.Width) * pos.X
+	}
+
+	if pos.Y < 1 {
+		abs.Y = float32(size.Height) * pos.Y
+	}
+
+	return abs, nil
+}
+
+func (point Point) relative() bool {
+	return false
+}
+
+func (point Point) abs(size *WindowSize) (*Position, error) {
+	if point.X < 0 || point.Y < 0 {
+		return nil, fmt.Errorf("Invalid point %v", point)
+	}
+
+	return &Position{X: float32(point.X), Y: float32(point.Y)}, nil
+}
+
+func (point RelPoint) relative() bool {
+	return true
+}
+
+func (point RelPoint) abs(size *WindowSize) (*Position, error) {
+	if point.X < 0 || point.X > 1 || point.Y < 0 || point.Y > 1 {
+		return nil, fmt.Errorf("Invalid relative point %v", point)
+	}
+
+	return &Position{
+		X: float32(size.Width) * point.X,
+		Y: float32(size.Height) * point.Y,
+	}, nil
 }
 
 /*
-Convert related position to absolute position
+Round the absolute position to the point
 */
-func (ua *UIAutomator) rel2abs(rel *Position) *Position {
-	if rel == nil {
-		rel = &Position{}
+func pointOf(x float32, y float32) Point {
+	return Point{
+		X: int(math.Round(float64(x))),
+		Y: int(math.Round(float64(y))),
 	}
+}
 
-	abs := &Position{
-		X: rel.X,
-		Y: rel.Y,
+/*
+Get the window size of the current rotation, the cache is re-validated
+against the display rotation once it is older than _WINDOW_SIZE_TTL
+*/
+func (ua *UIAutomator) windowSize() (*WindowSize, error) {
+	if size := ua.cachedWindowSize(); size != nil {
+		return size, nil
 	}
-	size := &WindowSize{}
 
-	if rel.X < 1 || rel.Y < 1 {
-		if ua.size == nil {
-			size, _ = ua.GetWindowSize()
+	return ua.refreshWindowSize()
+}
 
-			// Cache the window size
-			ua.size = size
-		}
+/*
+Fetch the window size and the rotation from the device, ignore the cache
+*/
+func (ua *UIAutomator) refreshWindowSize() (*WindowSize, error) {
+	// The cache is refreshed by deviceInfo
+	if _, err := ua.GetDeviceInfo(); err != nil {
+		return nil, err
+	}
 
-		size = ua.size
+	ua.display.mutex.Lock()
+	defer ua.display.mutex.Unlock()
+
+	if ua.display.size == nil {
+		return nil, fmt.Errorf("Unknown window size")
 	}
 
-	if rel.X < 1 {
-		abs.X = float32(size.Width) * rel.X
+	return ua.display.size, nil
+}
+
+func (ua *UIAutomator) cachedWindowSize() *WindowSize {
+	ua.display.mutex.Lock()
+	defer ua.display.mutex.Unlock()
+
+	if ua.display.size == nil || time.Since(ua.display.checked) > _WINDOW_SIZE_TTL {
+		return nil
 	}
 
-	if rel.Y < 1 {
-		abs.Y = float32(size.Height) * abs.Y
+	return ua.display.size
+}
+
+/*
+Update the cached window size, the size is kept if the rotation is not changed
+*/
+func (ua *UIAutomator) cacheWindowSize(info *DeviceInfo) {
+	ua.display.mutex.Lock()
+	defer ua.display.mutex.Unlock()
+
+	if info.DisplayWidth <= 0 || info.DisplayHeight <= 0 {
+		ua.display.size = nil
+		return
+	}
+
+	if ua.display.size == nil ||
+		ua.display.rotation != info.DisplayRotation ||
+		ua.display.size.Width != info.DisplayWidth ||
+		ua.display.size.Height != info.DisplayHeight {
+		ua.display.size = &WindowSize{
+			Width:  info.DisplayWidth,
+			Height: info.DisplayHeight,
+		}
+		ua.display.rotation = info.DisplayRotation
 	}
 
-	return abs
+	ua.display.checked = time.Now()
 }
 
 /*
-Click on the screen
+Drop the cached window size, e.g. the device is rotated outside
+*/
+func (ua *UIAutomator) InvalidateWindowSize() {
+	ua.display.mutex.Lock()
+	defer ua.display.mutex.Unlock()
+
+	ua.display.size = nil
+}
+
+/*
+Convert the coordinate to absolute position
 */
-func (ua *UIAutomator) Click(position *Position) error {
-	if position.X < 0 || position.Y < 0 {
-		return fmt.Errorf("Click: an invalid position %q", position)
+func (ua *UIAutomator) toAbs(coordinate Coordinate) (*Position, error) {
+	if coordinate == nil {
+		return nil, fmt.Errorf("Invalid coordinate <nil>")
+	}
+
+	var size *WindowSize
+	if coordinate.relative() {
+		var err error
+		if size, err = ua.windowSize(); err != nil {
+			return nil, err
+		}
 	}
 
-	abs := ua.rel2abs(position)
+	return coordinate.abs(size)
+}
+
+/*
+Click on the screen
+*/
+func (ua *UIAutomator) Click(coordinate Coordinate) error {
+	abs, err := ua.toAbs(coordinate)
+	if err != nil {
+		return fmt.Errorf("Click: %s", err)
+	}
 
 	return ua.post(
 		&RPCOptions{
@@ -77,22 +230,22 @@ func (ua *UIAutomator) Click(position *Position) error {
 /*
 Double click on the screen
 */
-func (ua *UIAutomator) DbClick(position *Position, duration float32) error {
-	if position.X < 0 || position.Y < 0 {
-		return fmt.Errorf("DbClick: an invalid position %q", position)
+func (ua *UIAutomator) DbClick(coordinate Coordinate, duration float32) error {
+	abs, err := ua.toAbs(coordinate)
+	if err != nil {
+		return fmt.Errorf("DbClick: %s", err)
 	}
-
-	abs := ua.rel2abs(position)
+	point := pointOf(abs.X, abs.Y)
 
 	// First click
-	if err := ua.Click(abs); err != nil {
+	if err := ua.Click(point); err != nil {
 		return err
 	}
 
 	time.Sleep(time.Duration(duration*1000) * time.Millisecond)
 
 	// Second click
-	if err := ua.Click(abs); err != nil {
+	if err := ua.Click(point); err != nil {
 		return err
 	}
 
@@ -125,13 +278,12 @@ func (ua *UIAutomator) touchMove(position *Position) error {
 /*
 Long click on the screen
 */
-func (ua *UIAutomator) LongClick(position *Position, duration float32) error {
-	if position.X < 0 || position.Y < 0 {
-		return fmt.Errorf("LongClick: an invalid position %q", position)
+func (ua *UIAutomator) LongClick(coordinate Coordinate, duration float32) error {
+	abs, err := ua.toAbs(coordinate)
+	if err != nil {
+		return fmt.Errorf("LongClick: %s", err)
 	}
 
-	abs := ua.rel2abs(position)
-
 	// Default duration is 0.5s
 	if duration == 0 {
 		duration = 0.5
@@ -152,18 +304,25 @@ func (ua *UIAutomator) LongClick(position *Position, duration float32) error {
 /*
 Swipe the screen
 */
-func (ua *UIAutomator) Swipe(from *Position, to *Position, step int) error {
-	if from.X < 0 || from.Y < 0 || to.X < 0 || to.Y < 0 {
-		return fmt.Errorf("Swipe: invalid from(%s) -> to(%s)", from, to)
+func (ua *UIAutomator) Swipe(from Coordinate, to Coordinate, step int) error {
+	start, err := ua.toAbs(from)
+	if err != nil {
+		return fmt.Errorf("Swipe: invalid from, %s", err)
 	}
 
-	from = ua.rel2abs(from)
-	to = ua.rel2abs(to)
+	end, err := ua.toAbs(to)
+	if err != nil {
+		return fmt.Errorf("Swipe: invalid to, %s", err)
+	}
+
+	if ua.human != nil {
+		return ua.humanSwipe([]*Position{start, end}, step)
+	}
 
 	return ua.post(
 		&RPCOptions{
 			Method: "swipe",
-			Params: []interface{}{from.X, from.Y, to.X, to.Y, step},
+			Params: []interface{}{start.X, start.Y, end.X, end.Y, step},
 		},
 		nil,
 		nil,
@@ -173,12 +332,23 @@ func (ua *UIAutomator) Swipe(from *Position, to *Position, step int) error {
 /*
 Swipe by points, unlock the gesture login
 */
-func (ua *UIAutomator) SwipePoints(points ...*Position) error {
-	var positions []int
+func (ua *UIAutomator) SwipePoints(points ...Coordinate) error {
+	var (
+		positions []int
+		absolutes []*Position
+	)
 
 	for _, v := range points {
-		abs := ua.rel2abs(v)
+		abs, err := ua.toAbs(v)
+		if err != nil {
+			return fmt.Errorf("SwipePoints: %s", err)
+		}
 		positions = append(positions, int(abs.X), int(abs.Y))
+		absolutes = append(absolutes, abs)
+	}
+
+	if ua.human != nil && len(absolutes) > 1 {
+		return ua.humanSwipe(absolutes, 20*(len(absolutes)-1))
 	}
 
 	return ua.post(
@@ -194,20 +364,156 @@ func (ua *UIAutomator) SwipePoints(points ...*Position) error {
 /*
 Swipe the screen
 */
-func (ua *UIAutomator) Drag(start *Position, end *Position, duration float32) error {
-	if start.X < 0 || start.Y < 0 || end.X < 0 || end.Y < 0 {
-		return fmt.Errorf("Drag: invalid start(%s) -> end(%s)", start, end)
+func (ua *UIAutomator) Drag(start Coordinate, end Coordinate, duration float32) error {
+	from, err := ua.toAbs(start)
+	if err != nil {
+		return fmt.Errorf("Drag: invalid start, %s", err)
+	}
+
+	to, err := ua.toAbs(end)
+	if err != nil {
+		return fmt.Errorf("Drag: invalid end, %s", err)
 	}
 
-	start = ua.rel2abs(start)
-	end = ua.rel2abs(end)
+	if ua.human != nil {
+		return ua.humanDrag(from, to, time.Duration(duration*1000)*time.Millisecond)
+	}
 
 	return ua.post(
 		&RPCOptions{
 			Method: "drag",
-			Params: []interface{}{start.X, start.Y, end.X, end.Y, duration * 200},
+			Params: []interface{}{from.X, from.Y, to.X, to.Y, duration * 200},
 		},
 		nil,
 		nil,
 	)
 }
+
+const (
+	DIRECTION_UP    = "up"
+	DIRECTION_DOWN  = "down"
+	DIRECTION_LEFT  = "left"
+	DIRECTION_RIGHT = "right"
+
+	_STATUS_BAR_DP     = 24
+	_NAVIGATION_BAR_DP = 48
+)
+
+type DIRECTION string
+
+/*
+Get the safe bounds of the screen, without the status bar and the navigation bar
+*/
+func (ua *UIAutomator) safeBounds() (*ElementRect, error) {
+	info, err := ua.GetDeviceInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	if info.DisplayWidth == 0 || info.DisplayHeight == 0 {
+		return nil, fmt.Errorf("Unknown display size")
+	}
+
+	density := float32(1)
+	if info.DisplaySizeDpX > 0 {
+		density = float32(info.DisplayWidth) / float32(info.DisplaySizeDpX)
+	}
+
+	return &ElementRect{
+		Left:   0,
+		Top:    int(_STATUS_BAR_DP * density),
+		Right:  info.DisplayWidth,
+		Bottom: info.DisplayHeight - int(_NAVIGATION_BAR_DP*density),
+	}, nil
+}
+
+/*
+Get the start and end points of the direction inside the bounds, scale is the ratio of the distance
+*/
+func directionPoints(bounds *ElementRect, direction DIRECTION, scale float32) (from Point, to Point, err error) {
+	if scale <= 0 || scale > 1 {
+		return from, to, fmt.Errorf("Invalid scale %v", scale)
+	}
+
+	width, height := float32(bounds.Right-bounds.Left), float32(bounds.Bottom-bounds.Top)
+	hOffset, vOffset := width*(1-scale)/2, height*(1-scale)/2
+	cx, cy := float32(bounds.Left)+width/2, float32(bounds.Top)+height/2
+
+	left := pointOf(float32(bounds.Left)+hOffset, cy)
+	right := pointOf(float32(bounds.Right)-hOffset, cy)
+	top := pointOf(cx, float32(bounds.Top)+vOffset)
+	bottom := pointOf(cx, float32(bounds.Bottom)-vOffset)
+
+	switch direction {
+	case DIRECTION_UP:
+		return bottom, top, nil
+	case DIRECTION_DOWN:
+		return top, bottom, nil
+	case DIRECTION_LEFT:
+		return right, left, nil
+	case DIRECTION_RIGHT:
+		return left, right, nil
+	}
+
+	return from, to, fmt.Errorf("Invalid direction %q", direction)
+}
+
+/*
+Swipe the screen to the direction, avoid the status bar and the navigation bar.
+scale is the ratio of the swipe distance to the screen, default is 0.9
+*/
+func (ua *UIAutomator) SwipeScreen(direction DIRECTION, scale float32, duration time.Duration) error {
+	if scale == 0 {
+		scale = 0.9
+	}
+
+	bounds, err := ua.safeBounds()
+	if err != nil {
+		return err
+	}
+
+	from, to, err := directionPoints(bounds, direction, scale)
+	if err != nil {
+		return fmt.Errorf("SwipeScreen: %s", err)
+	}
+
+	steps := int(duration / _UIAUTOMATOR_STEP)
+	if steps < 2 {
+		steps = 2
+	}
+
+	return ua.Swipe(from, to, steps)
+}
+
+/*
+Fling the screen to the direction with the velocity(pixels per second)
+*/
+func (ua *UIAutomator) Fling(direction DIRECTION, scale float32, velocity float32) error {
+	if velocity <= 0 {
+		return fmt.Errorf("Fling: invalid velocity %v", velocity)
+	}
+
+	if scale == 0 {
+		scale = 0.9
+	}
+
+	bounds, err := ua.safeBounds()
+	if err != nil {
+		return err
+	}
+
+	from, to, err := directionPoints(bounds, direction, scale)
+	if err != nil {
+		return fmt.Errorf("Fling: %s", err)
+	}
+
+	distance := math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y))
+	duration := time.Duration(distance / float64(velocity) * float64(time.Second))
+
+	steps := int(duration / _UIAUTOMATOR_STEP)
+	if steps < 2 {
+		steps = 2
+	}
+
+	return ua.Swipe(from, to, steps)
+}
@@ -0,0 +1,124 @@
+package uiautomator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCoordinateAbs(t *testing.T) {
+	size := &WindowSize{Width: 1080, Height: 2400}
+
+	cases := []struct {
+		name       string
+		coordinate Coordinate
+		want       *Position
+	}{
+		{"position pixels", &Position{X: 540, Y: 1200}, &Position{X: 540, Y: 1200}},
+		{"position fractions", &Position{X: 0.5, Y: 0.25}, &Position{X: 540, Y: 600}},
+		{"position mixed", &Position{X: 0.5, Y: 100}, &Position{X: 540, Y: 100}},
+		{"position origin", &Position{X: 0, Y: 0}, &Position{X: 0, Y: 0}},
+		{"point", Point{X: 10, Y: 20}, &Position{X: 10, Y: 20}},
+		{"point one pixel", Point{X: 1, Y: 0}, &Position{X: 1, Y: 0}},
+		{"relative point", RelPoint{X: 0.5, Y: 0.5}, &Position{X: 540, Y: 1200}},
+		{"relative point edge", RelPoint{X: 1, Y: 1}, &Position{X: 1080, Y: 2400}},
+	}
+
+	for _, c := range cases {
+		got, err := c.coordinate.abs(size)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.name, err)
+			continue
+		}
+
+		if *got != *c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestCoordinateAbsInvalid(t *testing.T) {
+	size := &WindowSize{Width: 1080, Height: 2400}
+
+	for _, coordinate := range []Coordinate{
+		(*Position)(nil),
+		&Position{X: -1, Y: 10},
+		Point{X: 10, Y: -1},
+		RelPoint{X: 1.5, Y: 0.5},
+		RelPoint{X: 0.5, Y: -0.1},
+	} {
+		if _, err := coordinate.abs(size); err == nil {
+			t.Errorf("%v: expected error", coordinate)
+		}
+	}
+}
+
+func TestCoordinateRelative(t *testing.T) {
+	if !(&Position{X: 0.5, Y: 100}).relative() {
+		t.Error("Position with a fraction should be relative")
+	}
+
+	if (&Position{X: 100, Y: 100}).relative() {
+		t.Error("Position in pixels should not be relative")
+	}
+
+	if (Point{X: 0, Y: 0}).relative() {
+		t.Error("Point should never be relative")
+	}
+
+	if !(RelPoint{X: 1, Y: 1}).relative() {
+		t.Error("RelPoint should always be relative")
+	}
+}
+
+func TestToAbs(t *testing.T) {
+	ua := &UIAutomator{display: &displayCache{}}
+	ua.cacheWindowSize(&DeviceInfo{DisplayWidth: 1080, DisplayHeight: 2400})
+
+	got, err := ua.toAbs(RelPoint{X: 0.5, Y: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != (Position{X: 540, Y: 1200}) {
+		t.Errorf("expected 540, 1200, got %v", got)
+	}
+
+	// Rotated to the landscape
+	ua.cacheWindowSize(&DeviceInfo{DisplayWidth: 2400, DisplayHeight: 1080, DisplayRotation: 1})
+
+	got, err = ua.toAbs(RelPoint{X: 0.5, Y: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != (Position{X: 1200, Y: 540}) {
+		t.Errorf("expected 1200, 540, got %v", got)
+	}
+
+	// The absolute coordinates never need the window size
+	ua.InvalidateWindowSize()
+
+	got, err = ua.toAbs(Point{X: 3, Y: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != (Position{X: 3, Y: 4}) {
+		t.Errorf("expected 3, 4, got %v", got)
+	}
+
+	if _, err := ua.toAbs(nil); err == nil {
+		t.Error("expected error for nil coordinate")
+	}
+}
+
+func TestWindowSizeCacheExpired(t *testing.T) {
+	ua := &UIAutomator{display: &displayCache{}}
+	ua.cacheWindowSize(&DeviceInfo{DisplayWidth: 1080, DisplayHeight: 2400})
+
+	if ua.cachedWindowSize() == nil {
+		t.Fatal("expected the fresh cache")
+	}
+
+	ua.display.checked = time.Now().Add(-_WINDOW_SIZE_TTL - time.Second)
+	if ua.cachedWindowSize() != nil {
+		t.Error("expected the expired cache to be re-validated")
+	}
+}
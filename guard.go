@@ -0,0 +1,206 @@
+/**
+Keep the scenario in the allowed apps
+*/
+package uiautomator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	GUARD_MAX_BACK = 2 // Default back pressing times before re-launch
+)
+
+type (
+	AppGuard struct {
+		Target   string             // The app re-launched on drift
+		Packages []string           // Allowed packages, the target is always allowed
+		Interval time.Duration      // Check periodically, 0 is disabled
+		MaxBack  int                // Back pressing times before re-launch
+		OnDrift  func(*AppIncident) // Report the incident
+
+		ua      *UIAutomator
+		mutex   sync.Mutex
+		stopped chan bool
+	}
+
+	AppIncident struct {
+		Time      time.Time
+		App       *AppInfo // The app drifted to
+		Recovered bool
+		Relaunch  bool  // Recovered by re-launch the target
+		Error     error // Failed to recover
+	}
+)
+
+/*
+Install the guard, it checks the foreground app before every element action.
+With the Interval, the guard checks in the background concurrently with the caller
+*/
+func (ua *UIAutomator) SetAppGuard(guard *AppGuard) error {
+	if guard != nil && guard.Target == "" {
+		return fmt.Errorf("SetAppGuard: target can not be empty")
+	}
+
+	if ua.guard != nil {
+		ua.guard.Stop()
+	}
+
+	ua.guard = guard
+	if guard == nil {
+		return nil
+	}
+
+	if guard.MaxBack <= 0 {
+		guard.MaxBack = GUARD_MAX_BACK
+	}
+
+	guard.ua = ua
+
+	if guard.Interval > 0 {
+		guard.stopped = make(chan bool)
+		go guard.watch(guard.stopped)
+	}
+
+	return nil
+}
+
+/*
+Get the installed guard
+*/
+func (ua *UIAutomator) GetAppGuard() *AppGuard {
+	return ua.guard
+}
+
+/*
+Check the guard if installed
+*/
+func (ua *UIAutomator) checkAppGuard() error {
+	if ua.guard == nil {
+		return nil
+	}
+
+	_, err := ua.guard.Check()
+	return err
+}
+
+/*
+Stop the periodic checking
+*/
+func (guard *AppGuard) Stop() {
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+
+	if guard.stopped != nil {
+		close(guard.stopped)
+		guard.stopped = nil
+	}
+}
+
+func (guard *AppGuard) watch(stopped chan bool) {
+	ticker := time.NewTicker(guard.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopped:
+			return
+		case <-ticker.C:
+			guard.Check()
+		}
+	}
+}
+
+func (guard *AppGuard) allowed(packageName string) bool {
+	if packageName == guard.Target {
+		return true
+	}
+
+	for _, allowed := range guard.Packages {
+		if packageName == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+Check the foreground app, recover it on drift.
+Returns the incident, nil if nothing drifted.
+OnDrift is called after the lock released, so it can use the elements
+*/
+func (guard *AppGuard) Check() (*AppIncident, error) {
+	incident, err := guard.check()
+	if incident == nil {
+		return nil, err
+	}
+
+	if guard.OnDrift != nil {
+		guard.OnDrift(incident)
+	}
+
+	return incident, incident.Error
+}
+
+func (guard *AppGuard) check() (*AppIncident, error) {
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+
+	info, err := guard.ua.GetCurrentApp()
+	if err != nil {
+		return nil, err
+	}
+
+	if guard.allowed(info.Package) {
+		return nil, nil
+	}
+
+	incident := &AppIncident{
+		Time: time.Now(),
+		App:  info,
+	}
+	incident.Relaunch, incident.Error = guard.recover()
+	incident.Recovered = incident.Error == nil
+
+	return incident, nil
+}
+
+/*
+Press back to leave the drifted app, re-launch the target if still drifted
+*/
+func (guard *AppGuard) recover() (relaunch bool, err error) {
+	ua := guard.ua
+
+	for i := 0; i < guard.MaxBack; i++ {
+		if err = ua.Press("back"); err != nil {
+			return
+		}
+
+		time.Sleep(time.Duration(500) * time.Millisecond)
+
+		info, err := ua.GetCurrentApp()
+		if err == nil && guard.allowed(info.Package) {
+			return false, nil
+		}
+	}
+
+	relaunch = true
+	if err = ua.AppStart(guard.Target); err != nil {
+		return
+	}
+
+	time.Sleep(time.Duration(1000) * time.Millisecond)
+
+	info, err := ua.GetCurrentApp()
+	if err != nil {
+		return
+	}
+
+	if !guard.allowed(info.Package) {
+		err = fmt.Errorf("AppGuard: failed to re-launch %q, %q in the foreground", guard.Target, info.Package)
+	}
+	return
+}
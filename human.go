@@ -0,0 +1,167 @@
+/**
+Human-like gesture paths for the apps rejecting the straight instant swipes
+*/
+package uiautomator
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	HUMAN_CURVATURE = 0.15 // Default max offset of the control points, ratio of the distance
+	HUMAN_JITTER    = 2    // Default max jitter(pixel)
+	HUMAN_POINTS    = 24   // Default points of a path
+)
+
+type HumanGestureOptions struct {
+	Seed      int64   // Seed of the random, the same seed generates the same paths
+	Curvature float32 // Max offset of the control points, ratio of the distance
+	Jitter    float32 // Max jitter of the points(pixel), 0 is HUMAN_JITTER, negative disables the jitter
+	Points    int     // Points of a path
+}
+
+/*
+Enable the human-like gestures on Swipe, Drag and SwipePoints, nil to disable.
+The zero options are the defaults, set a negative Jitter to disable the jitter
+*/
+func (ua *UIAutomator) SetHumanGesture(options *HumanGestureOptions) {
+	if options == nil {
+		ua.human, ua.humanRand = nil, nil
+		return
+	}
+
+	if options.Curvature <= 0 {
+		options.Curvature = HUMAN_CURVATURE
+	}
+
+	if options.Jitter < 0 {
+		options.Jitter = 0
+	} else if options.Jitter == 0 {
+		options.Jitter = HUMAN_JITTER
+	}
+
+	if options.Points < 2 {
+		options.Points = HUMAN_POINTS
+	}
+
+	ua.human = options
+	ua.humanRand = rand.New(rand.NewSource(options.Seed))
+}
+
+/*
+Generate a curved path from a cubic Bezier, the points are dense at both ends(slow)
+and sparse in the middle(fast), the interior points are jittered
+*/
+func humanPath(from *Position, to *Position, options *HumanGestureOptions, random *rand.Rand) []*Position {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	distance := float32(math.Hypot(float64(dx), float64(dy)))
+
+	// Unit normal of the direction
+	var nx, ny float32
+	if distance > 0 {
+		nx, ny = -dy/distance, dx/distance
+	}
+
+	offset := func() float32 {
+		return (random.Float32()*2 - 1) * options.Curvature * distance
+	}
+
+	// Control points at about 1/3 and 2/3, pushed aside randomly
+	o1, o2 := offset(), offset()
+	c1 := &Position{X: from.X + dx/3 + nx*o1, Y: from.Y + dy/3 + ny*o1}
+	c2 := &Position{X: from.X + dx*2/3 + nx*o2, Y: from.Y + dy*2/3 + ny*o2}
+
+	count := options.Points
+	path := make([]*Position, 0, count)
+
+	for i := 0; i < count; i++ {
+		t := float64(i) / float64(count-1)
+
+		// Variable speed, ease in and out with a little noise
+		if i > 0 && i < count-1 {
+			t += (random.Float64()*2 - 1) * 0.3 / float64(count)
+			t = math.Max(0, math.Min(1, t))
+		}
+		s := float32(t * t * (3 - 2*t))
+
+		u := 1 - s
+		point := &Position{
+			X: u*u*u*from.X + 3*u*u*s*c1.X + 3*u*s*s*c2.X + s*s*s*to.X,
+			Y: u*u*u*from.Y + 3*u*u*s*c1.Y + 3*u*s*s*c2.Y + s*s*s*to.Y,
+		}
+
+		if i > 0 && i < count-1 {
+			point.X += (random.Float32()*2 - 1) * options.Jitter
+			point.Y += (random.Float32()*2 - 1) * options.Jitter
+		}
+
+		path = append(path, &Position{X: float32(math.Max(0, float64(point.X))), Y: float32(math.Max(0, float64(point.Y)))})
+	}
+
+	return path
+}
+
+/*
+Swipe through the absolute positions along the human-like paths, a step is about 5ms
+*/
+func (ua *UIAutomator) humanSwipe(positions []*Position, steps int) error {
+	var points []int
+
+	for i := 1; i < len(positions); i++ {
+		path := humanPath(positions[i-1], positions[i], ua.human, ua.humanRand)
+
+		// The joint point is in both paths
+		if i > 1 {
+			path = path[1:]
+		}
+
+		for _, point := range path {
+			points = append(points, int(point.X), int(point.Y))
+		}
+	}
+
+	// Steps of every segment
+	segments := len(points)/2 - 1
+	segmentSteps := 1
+	if segments > 0 && steps/segments > 1 {
+		segmentSteps = steps / segments
+	}
+
+	return ua.post(
+		&RPCOptions{
+			Method: "swipePoints",
+			Params: []interface{}{points, segmentSteps},
+		},
+		nil,
+		nil,
+	)
+}
+
+/*
+Drag along the human-like path via the injected touch moves
+*/
+func (ua *UIAutomator) humanDrag(from *Position, to *Position, duration time.Duration) error {
+	path := humanPath(from, to, ua.human, ua.humanRand)
+
+	if err := ua.touchDown(path[0]); err != nil {
+		return err
+	}
+
+	// Hold a while before moving, as the drag is started by a long press
+	time.Sleep(time.Duration(500) * time.Millisecond)
+
+	interval := duration / time.Duration(len(path)-1)
+	for i, point := range path[1:] {
+		time.Sleep(interval)
+
+		if err := ua.touchMove(point); err != nil {
+			// Release the pointer at the last reached point
+			ua.touchUp(path[i])
+			return err
+		}
+	}
+
+	return ua.touchUp(path[len(path)-1])
+}
@@ -0,0 +1,700 @@
+/**
+Android key codes
+https://developer.android.com/reference/android/view/KeyEvent
+*/
+package uiautomator
+
+const (
+	KEYCODE_UNKNOWN                       Key = 0
+	KEYCODE_SOFT_LEFT                     Key = 1
+	KEYCODE_SOFT_RIGHT                    Key = 2
+	KEYCODE_HOME                          Key = 3
+	KEYCODE_BACK                          Key = 4
+	KEYCODE_CALL                          Key = 5
+	KEYCODE_ENDCALL                       Key = 6
+	KEYCODE_0                             Key = 7
+	KEYCODE_1                             Key = 8
+	KEYCODE_2                             Key = 9
+	KEYCODE_3                             Key = 10
+	KEYCODE_4                             Key = 11
+	KEYCODE_5                             Key = 12
+	KEYCODE_6                             Key = 13
+	KEYCODE_7                             Key = 14
+	KEYCODE_8                             Key = 15
+	KEYCODE_9                             Key = 16
+	KEYCODE_STAR                          Key = 17
+	KEYCODE_POUND                         Key = 18
+	KEYCODE_DPAD_UP                       Key = 19
+	KEYCODE_DPAD_DOWN                     Key = 20
+	KEYCODE_DPAD_LEFT                     Key = 21
+	KEYCODE_DPAD_RIGHT                    Key = 22
+	KEYCODE_DPAD_CENTER                   Key = 23
+	KEYCODE_VOLUME_UP                     Key = 24
+	KEYCODE_VOLUME_DOWN                   Key = 25
+	KEYCODE_POWER                         Key = 26
+	KEYCODE_CAMERA                        Key = 27
+	KEYCODE_CLEAR                         Key = 28
+	KEYCODE_A                             Key = 29
+	KEYCODE_B                             Key = 30
+	KEYCODE_C                             Key = 31
+	KEYCODE_D                             Key = 32
+	KEYCODE_E                             Key = 33
+	KEYCODE_F                             Key = 34
+	KEYCODE_G                             Key = 35
+	KEYCODE_H                             Key = 36
+	KEYCODE_I                             Key = 37
+	KEYCODE_J                             Key = 38
+	KEYCODE_K                             Key = 39
+	KEYCODE_L                             Key = 40
+	KEYCODE_M                             Key = 41
+	KEYCODE_N                             Key = 42
+	KEYCODE_O                             Key = 43
+	KEYCODE_P                             Key = 44
+	KEYCODE_Q                             Key = 45
+	KEYCODE_R                             Key = 46
+	KEYCODE_S                             Key = 47
+	KEYCODE_T                             Key = 48
+	KEYCODE_U                             Key = 49
+	KEYCODE_V                             Key = 50
+	KEYCODE_W                             Key = 51
+	KEYCODE_X                             Key = 52
+	KEYCODE_Y                             Key = 53
+	KEYCODE_Z                             Key = 54
+	KEYCODE_COMMA                         Key = 55
+	KEYCODE_PERIOD                        Key = 56
+	KEYCODE_ALT_LEFT                      Key = 57
+	KEYCODE_ALT_RIGHT                     Key = 58
+	KEYCODE_SHIFT_LEFT                    Key = 59
+	KEYCODE_SHIFT_RIGHT                   Key = 60
+	KEYCODE_TAB                           Key = 61
+	KEYCODE_SPACE                         Key = 62
+	KEYCODE_SYM                           Key = 63
+	KEYCODE_EXPLORER                      Key = 64
+	KEYCODE_ENVELOPE                      Key = 65
+	KEYCODE_ENTER                         Key = 66
+	KEYCODE_DEL                           Key = 67
+	KEYCODE_GRAVE                         Key = 68
+	KEYCODE_MINUS                         Key = 69
+	KEYCODE_EQUALS                        Key = 70
+	KEYCODE_LEFT_BRACKET                  Key = 71
+	KEYCODE_RIGHT_BRACKET                 Key = 72
+	KEYCODE_BACKSLASH                     Key = 73
+	KEYCODE_SEMICOLON                     Key = 74
+	KEYCODE_APOSTROPHE                    Key = 75
+	KEYCODE_SLASH                         Key = 76
+	KEYCODE_AT                            Key = 77
+	KEYCODE_NUM                           Key = 78
+	KEYCODE_HEADSETHOOK                   Key = 79
+	KEYCODE_FOCUS                         Key = 80
+	KEYCODE_PLUS                          Key = 81
+	KEYCODE_MENU                          Key = 82
+	KEYCODE_NOTIFICATION                  Key = 83
+	KEYCODE_SEARCH                        Key = 84
+	KEYCODE_MEDIA_PLAY_PAUSE              Key = 85
+	KEYCODE_MEDIA_STOP                    Key = 86
+	KEYCODE_MEDIA_NEXT                    Key = 87
+	KEYCODE_MEDIA_PREVIOUS                Key = 88
+	KEYCODE_MEDIA_REWIND                  Key = 89
+	KEYCODE_MEDIA_FAST_FORWARD            Key = 90
+	KEYCODE_MUTE                          Key = 91
+	KEYCODE_PAGE_UP                       Key = 92
+	KEYCODE_PAGE_DOWN                     Key = 93
+	KEYCODE_PICTSYMBOLS                   Key = 94
+	KEYCODE_SWITCH_CHARSET                Key = 95
+	KEYCODE_BUTTON_A                      Key = 96
+	KEYCODE_BUTTON_B                      Key = 97
+	KEYCODE_BUTTON_C                      Key = 98
+	KEYCODE_BUTTON_X                      Key = 99
+	KEYCODE_BUTTON_Y                      Key = 100
+	KEYCODE_BUTTON_Z                      Key = 101
+	KEYCODE_BUTTON_L1                     Key = 102
+	KEYCODE_BUTTON_R1                     Key = 103
+	KEYCODE_BUTTON_L2                     Key = 104
+	KEYCODE_BUTTON_R2                     Key = 105
+	KEYCODE_BUTTON_THUMBL                 Key = 106
+	KEYCODE_BUTTON_THUMBR                 Key = 107
+	KEYCODE_BUTTON_START                  Key = 108
+	KEYCODE_BUTTON_SELECT                 Key = 109
+	KEYCODE_BUTTON_MODE                   Key = 110
+	KEYCODE_ESCAPE                        Key = 111
+	KEYCODE_FORWARD_DEL                   Key = 112
+	KEYCODE_CTRL_LEFT                     Key = 113
+	KEYCODE_CTRL_RIGHT                    Key = 114
+	KEYCODE_CAPS_LOCK                     Key = 115
+	KEYCODE_SCROLL_LOCK                   Key = 116
+	KEYCODE_META_LEFT                     Key = 117
+	KEYCODE_META_RIGHT                    Key = 118
+	KEYCODE_FUNCTION                      Key = 119
+	KEYCODE_SYSRQ                         Key = 120
+	KEYCODE_BREAK                         Key = 121
+	KEYCODE_MOVE_HOME                     Key = 122
+	KEYCODE_MOVE_END                      Key = 123
+	KEYCODE_INSERT                        Key = 124
+	KEYCODE_FORWARD                       Key = 125
+	KEYCODE_MEDIA_PLAY                    Key = 126
+	KEYCODE_MEDIA_PAUSE                   Key = 127
+	KEYCODE_MEDIA_CLOSE                   Key = 128
+	KEYCODE_MEDIA_EJECT                   Key = 129
+	KEYCODE_MEDIA_RECORD                  Key = 130
+	KEYCODE_F1                            Key = 131
+	KEYCODE_F2                            Key = 132
+	KEYCODE_F3                            Key = 133
+	KEYCODE_F4                            Key = 134
+	KEYCODE_F5                            Key = 135
+	KEYCODE_F6                            Key = 136
+	KEYCODE_F7                            Key = 137
+	KEYCODE_F8                            Key = 138
+	KEYCODE_F9                            Key = 139
+	KEYCODE_F10                           Key = 140
+	KEYCODE_F11                           Key = 141
+	KEYCODE_F12                           Key = 142
+	KEYCODE_NUM_LOCK                      Key = 143
+	KEYCODE_NUMPAD_0                      Key = 144
+	KEYCODE_NUMPAD_1                      Key = 145
+	KEYCODE_NUMPAD_2                      Key = 146
+	KEYCODE_NUMPAD_3                      Key = 147
+	KEYCODE_NUMPAD_4                      Key = 148
+	KEYCODE_NUMPAD_5                      Key = 149
+	KEYCODE_NUMPAD_6                      Key = 150
+	KEYCODE_NUMPAD_7                      Key = 151
+	KEYCODE_NUMPAD_8                      Key = 152
+	KEYCODE_NUMPAD_9                      Key = 153
+	KEYCODE_NUMPAD_DIVIDE                 Key = 154
+	KEYCODE_NUMPAD_MULTIPLY               Key = 155
+	KEYCODE_NUMPAD_SUBTRACT               Key = 156
+	KEYCODE_NUMPAD_ADD                    Key = 157
+	KEYCODE_NUMPAD_DOT                    Key = 158
+	KEYCODE_NUMPAD_COMMA                  Key = 159
+	KEYCODE_NUMPAD_ENTER                  Key = 160
+	KEYCODE_NUMPAD_EQUALS                 Key = 161
+	KEYCODE_NUMPAD_LEFT_PAREN             Key = 162
+	KEYCODE_NUMPAD_RIGHT_PAREN            Key = 163
+	KEYCODE_VOLUME_MUTE                   Key = 164
+	KEYCODE_INFO                          Key = 165
+	KEYCODE_CHANNEL_UP                    Key = 166
+	KEYCODE_CHANNEL_DOWN                  Key = 167
+	KEYCODE_ZOOM_IN                       Key = 168
+	KEYCODE_ZOOM_OUT                      Key = 169
+	KEYCODE_TV                            Key = 170
+	KEYCODE_WINDOW                        Key = 171
+	KEYCODE_GUIDE                         Key = 172
+	KEYCODE_DVR                           Key = 173
+	KEYCODE_BOOKMARK                      Key = 174
+	KEYCODE_CAPTIONS                      Key = 175
+	KEYCODE_SETTINGS                      Key = 176
+	KEYCODE_TV_POWER                      Key = 177
+	KEYCODE_TV_INPUT                      Key = 178
+	KEYCODE_STB_POWER                     Key = 179
+	KEYCODE_STB_INPUT                     Key = 180
+	KEYCODE_AVR_POWER                     Key = 181
+	KEYCODE_AVR_INPUT                     Key = 182
+	KEYCODE_PROG_RED                      Key = 183
+	KEYCODE_PROG_GREEN                    Key = 184
+	KEYCODE_PROG_YELLOW                   Key = 185
+	KEYCODE_PROG_BLUE                     Key = 186
+	KEYCODE_APP_SWITCH                    Key = 187
+	KEYCODE_BUTTON_1                      Key = 188
+	KEYCODE_BUTTON_2                      Key = 189
+	KEYCODE_BUTTON_3                      Key = 190
+	KEYCODE_BUTTON_4                      Key = 191
+	KEYCODE_BUTTON_5                      Key = 192
+	KEYCODE_BUTTON_6                      Key = 193
+	KEYCODE_BUTTON_7                      Key = 194
+	KEYCODE_BUTTON_8                      Key = 195
+	KEYCODE_BUTTON_9                      Key = 196
+	KEYCODE_BUTTON_10                     Key = 197
+	KEYCODE_BUTTON_11                     Key = 198
+	KEYCODE_BUTTON_12                     Key = 199
+	KEYCODE_BUTTON_13                     Key = 200
+	KEYCODE_BUTTON_14                     Key = 201
+	KEYCODE_BUTTON_15                     Key = 202
+	KEYCODE_BUTTON_16                     Key = 203
+	KEYCODE_LANGUAGE_SWITCH               Key = 204
+	KEYCODE_MANNER_MODE                   Key = 205
+	KEYCODE_3D_MODE                       Key = 206
+	KEYCODE_CONTACTS                      Key = 207
+	KEYCODE_CALENDAR                      Key = 208
+	KEYCODE_MUSIC                         Key = 209
+	KEYCODE_CALCULATOR                    Key = 210
+	KEYCODE_ZENKAKU_HANKAKU               Key = 211
+	KEYCODE_EISU                          Key = 212
+	KEYCODE_MUHENKAN                      Key = 213
+	KEYCODE_HENKAN                        Key = 214
+	KEYCODE_KATAKANA_HIRAGANA             Key = 215
+	KEYCODE_YEN                           Key = 216
+	KEYCODE_RO                            Key = 217
+	KEYCODE_KANA                          Key = 218
+	KEYCODE_ASSIST                        Key = 219
+	KEYCODE_BRIGHTNESS_DOWN               Key = 220
+	KEYCODE_BRIGHTNESS_UP                 Key = 221
+	KEYCODE_MEDIA_AUDIO_TRACK             Key = 222
+	KEYCODE_SLEEP                         Key = 223
+	KEYCODE_WAKEUP                        Key = 224
+	KEYCODE_PAIRING                       Key = 225
+	KEYCODE_MEDIA_TOP_MENU                Key = 226
+	KEYCODE_11                            Key = 227
+	KEYCODE_12                            Key = 228
+	KEYCODE_LAST_CHANNEL                  Key = 229
+	KEYCODE_TV_DATA_SERVICE               Key = 230
+	KEYCODE_VOICE_ASSIST                  Key = 231
+	KEYCODE_TV_RADIO_SERVICE              Key = 232
+	KEYCODE_TV_TELETEXT                   Key = 233
+	KEYCODE_TV_NUMBER_ENTRY               Key = 234
+	KEYCODE_TV_TERRESTRIAL_ANALOG         Key = 235
+	KEYCODE_TV_TERRESTRIAL_DIGITAL        Key = 236
+	KEYCODE_TV_SATELLITE                  Key = 237
+	KEYCODE_TV_SATELLITE_BS               Key = 238
+	KEYCODE_TV_SATELLITE_CS               Key = 239
+	KEYCODE_TV_SATELLITE_SERVICE          Key = 240
+	KEYCODE_TV_NETWORK                    Key = 241
+	KEYCODE_TV_ANTENNA_CABLE              Key = 242
+	KEYCODE_TV_INPUT_HDMI_1               Key = 243
+	KEYCODE_TV_INPUT_HDMI_2               Key = 244
+	KEYCODE_TV_INPUT_HDMI_3               Key = 245
+	KEYCODE_TV_INPUT_HDMI_4               Key = 246
+	KEYCODE_TV_INPUT_COMPOSITE_1          Key = 247
+	KEYCODE_TV_INPUT_COMPOSITE_2          Key = 248
+	KEYCODE_TV_INPUT_COMPONENT_1          Key = 249
+	KEYCODE_TV_INPUT_COMPONENT_2          Key = 250
+	KEYCODE_TV_INPUT_VGA_1                Key = 251
+	KEYCODE_TV_AUDIO_DESCRIPTION          Key = 252
+	KEYCODE_TV_AUDIO_DESCRIPTION_MIX_UP   Key = 253
+	KEYCODE_TV_AUDIO_DESCRIPTION_MIX_DOWN Key = 254
+	KEYCODE_TV_ZOOM_MODE                  Key = 255
+	KEYCODE_TV_CONTENTS_MENU              Key = 256
+	KEYCODE_TV_MEDIA_CONTEXT_MENU         Key = 257
+	KEYCODE_TV_TIMER_PROGRAMMING          Key = 258
+	KEYCODE_HELP                          Key = 259
+	KEYCODE_NAVIGATE_PREVIOUS             Key = 260
+	KEYCODE_NAVIGATE_NEXT                 Key = 261
+	KEYCODE_NAVIGATE_IN                   Key = 262
+	KEYCODE_NAVIGATE_OUT                  Key = 263
+	KEYCODE_STEM_PRIMARY                  Key = 264
+	KEYCODE_STEM_1                        Key = 265
+	KEYCODE_STEM_2                        Key = 266
+	KEYCODE_STEM_3                        Key = 267
+	KEYCODE_DPAD_UP_LEFT                  Key = 268
+	KEYCODE_DPAD_DOWN_LEFT                Key = 269
+	KEYCODE_DPAD_UP_RIGHT                 Key = 270
+	KEYCODE_DPAD_DOWN_RIGHT               Key = 271
+	KEYCODE_MEDIA_SKIP_FORWARD            Key = 272
+	KEYCODE_MEDIA_SKIP_BACKWARD           Key = 273
+	KEYCODE_MEDIA_STEP_FORWARD            Key = 274
+	KEYCODE_MEDIA_STEP_BACKWARD           Key = 275
+	KEYCODE_SOFT_SLEEP                    Key = 276
+	KEYCODE_CUT                           Key = 277
+	KEYCODE_COPY                          Key = 278
+	KEYCODE_PASTE                         Key = 279
+	KEYCODE_SYSTEM_NAVIGATION_UP          Key = 280
+	KEYCODE_SYSTEM_NAVIGATION_DOWN        Key = 281
+	KEYCODE_SYSTEM_NAVIGATION_LEFT        Key = 282
+	KEYCODE_SYSTEM_NAVIGATION_RIGHT       Key = 283
+	KEYCODE_ALL_APPS                      Key = 284
+	KEYCODE_REFRESH                       Key = 285
+	KEYCODE_THUMBS_UP                     Key = 286
+	KEYCODE_THUMBS_DOWN                   Key = 287
+	KEYCODE_PROFILE_SWITCH                Key = 288
+	KEYCODE_VIDEO_APP_1                   Key = 289
+	KEYCODE_VIDEO_APP_2                   Key = 290
+	KEYCODE_VIDEO_APP_3                   Key = 291
+	KEYCODE_VIDEO_APP_4                   Key = 292
+	KEYCODE_VIDEO_APP_5                   Key = 293
+	KEYCODE_VIDEO_APP_6                   Key = 294
+	KEYCODE_VIDEO_APP_7                   Key = 295
+	KEYCODE_VIDEO_APP_8                   Key = 296
+	KEYCODE_FEATURED_APP_1                Key = 297
+	KEYCODE_FEATURED_APP_2                Key = 298
+	KEYCODE_FEATURED_APP_3                Key = 299
+	KEYCODE_FEATURED_APP_4                Key = 300
+	KEYCODE_DEMO_APP_1                    Key = 301
+	KEYCODE_DEMO_APP_2                    Key = 302
+	KEYCODE_DEMO_APP_3                    Key = 303
+	KEYCODE_DEMO_APP_4                    Key = 304
+	KEYCODE_KEYBOARD_BACKLIGHT_DOWN       Key = 305
+	KEYCODE_KEYBOARD_BACKLIGHT_UP         Key = 306
+	KEYCODE_KEYBOARD_BACKLIGHT_TOGGLE     Key = 307
+	KEYCODE_STYLUS_BUTTON_PRIMARY         Key = 308
+	KEYCODE_STYLUS_BUTTON_SECONDARY       Key = 309
+	KEYCODE_STYLUS_BUTTON_TERTIARY        Key = 310
+	KEYCODE_STYLUS_BUTTON_TAIL            Key = 311
+	KEYCODE_RECENT_APPS                   Key = 312
+	KEYCODE_MACRO_1                       Key = 313
+	KEYCODE_MACRO_2                       Key = 314
+	KEYCODE_MACRO_3                       Key = 315
+	KEYCODE_MACRO_4                       Key = 316
+)
+
+const (
+	META_SHIFT_ON       MetaState = 0x01
+	META_ALT_ON         MetaState = 0x02
+	META_SYM_ON         MetaState = 0x04
+	META_FUNCTION_ON    MetaState = 0x08
+	META_ALT_LEFT_ON    MetaState = 0x10
+	META_ALT_RIGHT_ON   MetaState = 0x20
+	META_SHIFT_LEFT_ON  MetaState = 0x40
+	META_SHIFT_RIGHT_ON MetaState = 0x80
+	META_CTRL_ON        MetaState = 0x1000
+	META_CTRL_LEFT_ON   MetaState = 0x2000
+	META_CTRL_RIGHT_ON  MetaState = 0x4000
+	META_META_ON        MetaState = 0x10000
+	META_META_LEFT_ON   MetaState = 0x20000
+	META_META_RIGHT_ON  MetaState = 0x40000
+	META_CAPS_LOCK_ON   MetaState = 0x100000
+	META_NUM_LOCK_ON    MetaState = 0x200000
+	META_SCROLL_LOCK_ON MetaState = 0x400000
+
+	// All the meta states of KeyEvent
+	META_MASK = META_SHIFT_ON | META_ALT_ON | META_SYM_ON | META_FUNCTION_ON |
+		META_ALT_LEFT_ON | META_ALT_RIGHT_ON | META_SHIFT_LEFT_ON | META_SHIFT_RIGHT_ON |
+		META_CTRL_ON | META_CTRL_LEFT_ON | META_CTRL_RIGHT_ON |
+		META_META_ON | META_META_LEFT_ON | META_META_RIGHT_ON |
+		META_CAPS_LOCK_ON | META_NUM_LOCK_ON | META_SCROLL_LOCK_ON
+)
+
+type (
+	Key int
+
+	MetaState int
+)
+
+var _KEY_NAMES = map[Key]string{
+	KEYCODE_UNKNOWN:                       "UNKNOWN",
+	KEYCODE_SOFT_LEFT:                     "SOFT_LEFT",
+	KEYCODE_SOFT_RIGHT:                    "SOFT_RIGHT",
+	KEYCODE_HOME:                          "HOME",
+	KEYCODE_BACK:                          "BACK",
+	KEYCODE_CALL:                          "CALL",
+	KEYCODE_ENDCALL:                       "ENDCALL",
+	KEYCODE_0:                             "0",
+	KEYCODE_1:                             "1",
+	KEYCODE_2:                             "2",
+	KEYCODE_3:                             "3",
+	KEYCODE_4:                             "4",
+	KEYCODE_5:                             "5",
+	KEYCODE_6:                             "6",
+	KEYCODE_7:                             "7",
+	KEYCODE_8:                             "8",
+	KEYCODE_9:                             "9",
+	KEYCODE_STAR:                          "STAR",
+	KEYCODE_POUND:                         "POUND",
+	KEYCODE_DPAD_UP:                       "DPAD_UP",
+	KEYCODE_DPAD_DOWN:                     "DPAD_DOWN",
+	KEYCODE_DPAD_LEFT:                     "DPAD_LEFT",
+	KEYCODE_DPAD_RIGHT:                    "DPAD_RIGHT",
+	KEYCODE_DPAD_CENTER:                   "DPAD_CENTER",
+	KEYCODE_VOLUME_UP:                     "VOLUME_UP",
+	KEYCODE_VOLUME_DOWN:                   "VOLUME_DOWN",
+	KEYCODE_POWER:                         "POWER",
+	KEYCODE_CAMERA:                        "CAMERA",
+	KEYCODE_CLEAR:                         "CLEAR",
+	KEYCODE_A:                             "A",
+	KEYCODE_B:                             "B",
+	KEYCODE_C:                             "C",
+	KEYCODE_D:                             "D",
+	KEYCODE_E:                             "E",
+	KEYCODE_F:                             "F",
+	KEYCODE_G:                             "G",
+	KEYCODE_H:                             "H",
+	KEYCODE_I:                             "I",
+	KEYCODE_J:                             "J",
+	KEYCODE_K:                             "K",
+	KEYCODE_L:                             "L",
+	KEYCODE_M:                             "M",
+	KEYCODE_N:                             "N",
+	KEYCODE_O:                             "O",
+	KEYCODE_P:                             "P",
+	KEYCODE_Q:                             "Q",
+	KEYCODE_R:                             "R",
+	KEYCODE_S:                             "S",
+	KEYCODE_T:                             "T",
+	KEYCODE_U:                             "U",
+	KEYCODE_V:                             "V",
+	KEYCODE_W:                             "W",
+	KEYCODE_X:                             "X",
+	KEYCODE_Y:                             "Y",
+	KEYCODE_Z:                             "Z",
+	KEYCODE_COMMA:                         "COMMA",
+	KEYCODE_PERIOD:                        "PERIOD",
+	KEYCODE_ALT_LEFT:                      "ALT_LEFT",
+	KEYCODE_ALT_RIGHT:                     "ALT_RIGHT",
+	KEYCODE_SHIFT_LEFT:                    "SHIFT_LEFT",
+	KEYCODE_SHIFT_RIGHT:                   "SHIFT_RIGHT",
+	KEYCODE_TAB:                           "TAB",
+	KEYCODE_SPACE:                         "SPACE",
+	KEYCODE_SYM:                           "SYM",
+	KEYCODE_EXPLORER:                      "EXPLORER",
+	KEYCODE_ENVELOPE:                      "ENVELOPE",
+	KEYCODE_ENTER:                         "ENTER",
+	KEYCODE_DEL:                           "DEL",
+	KEYCODE_GRAVE:                         "GRAVE",
+	KEYCODE_MINUS:                         "MINUS",
+	KEYCODE_EQUALS:                        "EQUALS",
+	KEYCODE_LEFT_BRACKET:                  "LEFT_BRACKET",
+	KEYCODE_RIGHT_BRACKET:                 "RIGHT_BRACKET",
+	KEYCODE_BACKSLASH:                     "BACKSLASH",
+	KEYCODE_SEMICOLON:                     "SEMICOLON",
+	KEYCODE_APOSTROPHE:                    "APOSTROPHE",
+	KEYCODE_SLASH:                         "SLASH",
+	KEYCODE_AT:                            "AT",
+	KEYCODE_NUM:                           "NUM",
+	KEYCODE_HEADSETHOOK:                   "HEADSETHOOK",
+	KEYCODE_FOCUS:                         "FOCUS",
+	KEYCODE_PLUS:                          "PLUS",
+	KEYCODE_MENU:                          "MENU",
+	KEYCODE_NOTIFICATION:                  "NOTIFICATION",
+	KEYCODE_SEARCH:                        "SEARCH",
+	KEYCODE_MEDIA_PLAY_PAUSE:              "MEDIA_PLAY_PAUSE",
+	KEYCODE_MEDIA_STOP:                    "MEDIA_STOP",
+	KEYCODE_MEDIA_NEXT:                    "MEDIA_NEXT",
+	KEYCODE_MEDIA_PREVIOUS:                "MEDIA_PREVIOUS",
+	KEYCODE_MEDIA_REWIND:                  "MEDIA_REWIND",
+	KEYCODE_MEDIA_FAST_FORWARD:            "MEDIA_FAST_FORWARD",
+	KEYCODE_MUTE:                          "MUTE",
+	KEYCODE_PAGE_UP:                       "PAGE_UP",
+	KEYCODE_PAGE_DOWN:                     "PAGE_DOWN",
+	KEYCODE_PICTSYMBOLS:                   "PICTSYMBOLS",
+	KEYCODE_SWITCH_CHARSET:                "SWITCH_CHARSET",
+	KEYCODE_BUTTON_A:                      "BUTTON_A",
+	KEYCODE_BUTTON_B:                      "BUTTON_B",
+	KEYCODE_BUTTON_C:                      "BUTTON_C",
+	KEYCODE_BUTTON_X:                      "BUTTON_X",
+	KEYCODE_BUTTON_Y:                      "BUTTON_Y",
+	KEYCODE_BUTTON_Z:                      "BUTTON_Z",
+	KEYCODE_BUTTON_L1:                     "BUTTON_L1",
+	KEYCODE_BUTTON_R1:                     "BUTTON_R1",
+	KEYCODE_BUTTON_L2:                     "BUTTON_L2",
+	KEYCODE_BUTTON_R2:                     "BUTTON_R2",
+	KEYCODE_BUTTON_THUMBL:                 "BUTTON_THUMBL",
+	KEYCODE_BUTTON_THUMBR:                 "BUTTON_THUMBR",
+	KEYCODE_BUTTON_START:                  "BUTTON_START",
+	KEYCODE_BUTTON_SELECT:                 "BUTTON_SELECT",
+	KEYCODE_BUTTON_MODE:                   "BUTTON_MODE",
+	KEYCODE_ESCAPE:                        "ESCAPE",
+	KEYCODE_FORWARD_DEL:                   "FORWARD_DEL",
+	KEYCODE_CTRL_LEFT:                     "CTRL_LEFT",
+	KEYCODE_CTRL_RIGHT:                    "CTRL_RIGHT",
+	KEYCODE_CAPS_LOCK:                     "CAPS_LOCK",
+	KEYCODE_SCROLL_LOCK:                   "SCROLL_LOCK",
+	KEYCODE_META_LEFT:                     "META_LEFT",
+	KEYCODE_META_RIGHT:                    "META_RIGHT",
+	KEYCODE_FUNCTION:                      "FUNCTION",
+	KEYCODE_SYSRQ:                         "SYSRQ",
+	KEYCODE_BREAK:                         "BREAK",
+	KEYCODE_MOVE_HOME:                     "MOVE_HOME",
+	KEYCODE_MOVE_END:                      "MOVE_END",
+	KEYCODE_INSERT:                        "INSERT",
+	KEYCODE_FORWARD:                       "FORWARD",
+	KEYCODE_MEDIA_PLAY:                    "MEDIA_PLAY",
+	KEYCODE_MEDIA_PAUSE:                   "MEDIA_PAUSE",
+	KEYCODE_MEDIA_CLOSE:                   "MEDIA_CLOSE",
+	KEYCODE_MEDIA_EJECT:                   "MEDIA_EJECT",
+	KEYCODE_MEDIA_RECORD:                  "MEDIA_RECORD",
+	KEYCODE_F1:                            "F1",
+	KEYCODE_F2:                            "F2",
+	KEYCODE_F3:                            "F3",
+	KEYCODE_F4:                            "F4",
+	KEYCODE_F5:                            "F5",
+	KEYCODE_F6:                            "F6",
+	KEYCODE_F7:                            "F7",
+	KEYCODE_F8:                            "F8",
+	KEYCODE_F9:                            "F9",
+	KEYCODE_F10:                           "F10",
+	KEYCODE_F11:                           "F11",
+	KEYCODE_F12:                           "F12",
+	KEYCODE_NUM_LOCK:                      "NUM_LOCK",
+	KEYCODE_NUMPAD_0:                      "NUMPAD_0",
+	KEYCODE_NUMPAD_1:                      "NUMPAD_1",
+	KEYCODE_NUMPAD_2:                      "NUMPAD_2",
+	KEYCODE_NUMPAD_3:                      "NUMPAD_3",
+	KEYCODE_NUMPAD_4:                      "NUMPAD_4",
+	KEYCODE_NUMPAD_5:                      "NUMPAD_5",
+	KEYCODE_NUMPAD_6:                      "NUMPAD_6",
+	KEYCODE_NUMPAD_7:                      "NUMPAD_7",
+	KEYCODE_NUMPAD_8:                      "NUMPAD_8",
+	KEYCODE_NUMPAD_9:                      "NUMPAD_9",
+	KEYCODE_NUMPAD_DIVIDE:                 "NUMPAD_DIVIDE",
+	KEYCODE_NUMPAD_MULTIPLY:               "NUMPAD_MULTIPLY",
+	KEYCODE_NUMPAD_SUBTRACT:               "NUMPAD_SUBTRACT",
+	KEYCODE_NUMPAD_ADD:                    "NUMPAD_ADD",
+	KEYCODE_NUMPAD_DOT:                    "NUMPAD_DOT",
+	KEYCODE_NUMPAD_COMMA:                  "NUMPAD_COMMA",
+	KEYCODE_NUMPAD_ENTER:                  "NUMPAD_ENTER",
+	KEYCODE_NUMPAD_EQUALS:                 "NUMPAD_EQUALS",
+	KEYCODE_NUMPAD_LEFT_PAREN:             "NUMPAD_LEFT_PAREN",
+	KEYCODE_NUMPAD_RIGHT_PAREN:            "NUMPAD_RIGHT_PAREN",
+	KEYCODE_VOLUME_MUTE:                   "VOLUME_MUTE",
+	KEYCODE_INFO:                          "INFO",
+	KEYCODE_CHANNEL_UP:                    "CHANNEL_UP",
+	KEYCODE_CHANNEL_DOWN:                  "CHANNEL_DOWN",
+	KEYCODE_ZOOM_IN:                       "ZOOM_IN",
+	KEYCODE_ZOOM_OUT:                      "ZOOM_OUT",
+	KEYCODE_TV:                            "TV",
+	KEYCODE_WINDOW:                        "WINDOW",
+	KEYCODE_GUIDE:                         "GUIDE",
+	KEYCODE_DVR:                           "DVR",
+	KEYCODE_BOOKMARK:                      "BOOKMARK",
+	KEYCODE_CAPTIONS:                      "CAPTIONS",
+	KEYCODE_SETTINGS:                      "SETTINGS",
+	KEYCODE_TV_POWER:                      "TV_POWER",
+	KEYCODE_TV_INPUT:                      "TV_INPUT",
+	KEYCODE_STB_POWER:                     "STB_POWER",
+	KEYCODE_STB_INPUT:                     "STB_INPUT",
+	KEYCODE_AVR_POWER:                     "AVR_POWER",
+	KEYCODE_AVR_INPUT:                     "AVR_INPUT",
+	KEYCODE_PROG_RED:                      "PROG_RED",
+	KEYCODE_PROG_GREEN:                    "PROG_GREEN",
+	KEYCODE_PROG_YELLOW:                   "PROG_YELLOW",
+	KEYCODE_PROG_BLUE:                     "PROG_BLUE",
+	KEYCODE_APP_SWITCH:                    "APP_SWITCH",
+	KEYCODE_BUTTON_1:                      "BUTTON_1",
+	KEYCODE_BUTTON_2:                      "BUTTON_2",
+	KEYCODE_BUTTON_3:                      "BUTTON_3",
+	KEYCODE_BUTTON_4:                      "BUTTON_4",
+	KEYCODE_BUTTON_5:                      "BUTTON_5",
+	KEYCODE_BUTTON_6:                      "BUTTON_6",
+	KEYCODE_BUTTON_7:                      "BUTTON_7",
+	KEYCODE_BUTTON_8:                      "BUTTON_8",
+	KEYCODE_BUTTON_9:                      "BUTTON_9",
+	KEYCODE_BUTTON_10:                     "BUTTON_10",
+	KEYCODE_BUTTON_11:                     "BUTTON_11",
+	KEYCODE_BUTTON_12:                     "BUTTON_12",
+	KEYCODE_BUTTON_13:                     "BUTTON_13",
+	KEYCODE_BUTTON_14:                     "BUTTON_14",
+	KEYCODE_BUTTON_15:                     "BUTTON_15",
+	KEYCODE_BUTTON_16:                     "BUTTON_16",
+	KEYCODE_LANGUAGE_SWITCH:               "LANGUAGE_SWITCH",
+	KEYCODE_MANNER_MODE:                   "MANNER_MODE",
+	KEYCODE_3D_MODE:                       "3D_MODE",
+	KEYCODE_CONTACTS:                      "CONTACTS",
+	KEYCODE_CALENDAR:                      "CALENDAR",
+	KEYCODE_MUSIC:                         "MUSIC",
+	KEYCODE_CALCULATOR:                    "CALCULATOR",
+	KEYCODE_ZENKAKU_HANKAKU:               "ZENKAKU_HANKAKU",
+	KEYCODE_EISU:                          "EISU",
+	KEYCODE_MUHENKAN:                      "MUHENKAN",
+	KEYCODE_HENKAN:                        "HENKAN",
+	KEYCODE_KATAKANA_HIRAGANA:             "KATAKANA_HIRAGANA",
+	KEYCODE_YEN:                           "YEN",
+	KEYCODE_RO:                            "RO",
+	KEYCODE_KANA:                          "KANA",
+	KEYCODE_ASSIST:                        "ASSIST",
+	KEYCODE_BRIGHTNESS_DOWN:               "BRIGHTNESS_DOWN",
+	KEYCODE_BRIGHTNESS_UP:                 "BRIGHTNESS_UP",
+	KEYCODE_MEDIA_AUDIO_TRACK:             "MEDIA_AUDIO_TRACK",
+	KEYCODE_SLEEP:                         "SLEEP",
+	KEYCODE_WAKEUP:                        "WAKEUP",
+	KEYCODE_PAIRING:                       "PAIRING",
+	KEYCODE_MEDIA_TOP_MENU:                "MEDIA_TOP_MENU",
+	KEYCODE_11:                            "11",
+	KEYCODE_12:                            "12",
+	KEYCODE_LAST_CHANNEL:                  "LAST_CHANNEL",
+	KEYCODE_TV_DATA_SERVICE:               "TV_DATA_SERVICE",
+	KEYCODE_VOICE_ASSIST:                  "VOICE_ASSIST",
+	KEYCODE_TV_RADIO_SERVICE:              "TV_RADIO_SERVICE",
+	KEYCODE_TV_TELETEXT:                   "TV_TELETEXT",
+	KEYCODE_TV_NUMBER_ENTRY:               "TV_NUMBER_ENTRY",
+	KEYCODE_TV_TERRESTRIAL_ANALOG:         "TV_TERRESTRIAL_ANALOG",
+	KEYCODE_TV_TERRESTRIAL_DIGITAL:        "TV_TERRESTRIAL_DIGITAL",
+	KEYCODE_TV_SATELLITE:                  "TV_SATELLITE",
+	KEYCODE_TV_SATELLITE_BS:               "TV_SATELLITE_BS",
+	KEYCODE_TV_SATELLITE_CS:               "TV_SATELLITE_CS",
+	KEYCODE_TV_SATELLITE_SERVICE:          "TV_SATELLITE_SERVICE",
+	KEYCODE_TV_NETWORK:                    "TV_NETWORK",
+	KEYCODE_TV_ANTENNA_CABLE:              "TV_ANTENNA_CABLE",
+	KEYCODE_TV_INPUT_HDMI_1:               "TV_INPUT_HDMI_1",
+	KEYCODE_TV_INPUT_HDMI_2:               "TV_INPUT_HDMI_2",
+	KEYCODE_TV_INPUT_HDMI_3:               "TV_INPUT_HDMI_3",
+	KEYCODE_TV_INPUT_HDMI_4:               "TV_INPUT_HDMI_4",
+	KEYCODE_TV_INPUT_COMPOSITE_1:          "TV_INPUT_COMPOSITE_1",
+	KEYCODE_TV_INPUT_COMPOSITE_2:          "TV_INPUT_COMPOSITE_2",
+	KEYCODE_TV_INPUT_COMPONENT_1:          "TV_INPUT_COMPONENT_1",
+	KEYCODE_TV_INPUT_COMPONENT_2:          "TV_INPUT_COMPONENT_2",
+	KEYCODE_TV_INPUT_VGA_1:                "TV_INPUT_VGA_1",
+	KEYCODE_TV_AUDIO_DESCRIPTION:          "TV_AUDIO_DESCRIPTION",
+	KEYCODE_TV_AUDIO_DESCRIPTION_MIX_UP:   "TV_AUDIO_DESCRIPTION_MIX_UP",
+	KEYCODE_TV_AUDIO_DESCRIPTION_MIX_DOWN: "TV_AUDIO_DESCRIPTION_MIX_DOWN",
+	KEYCODE_TV_ZOOM_MODE:                  "TV_ZOOM_MODE",
+	KEYCODE_TV_CONTENTS_MENU:              "TV_CONTENTS_MENU",
+	KEYCODE_TV_MEDIA_CONTEXT_MENU:         "TV_MEDIA_CONTEXT_MENU",
+	KEYCODE_TV_TIMER_PROGRAMMING:          "TV_TIMER_PROGRAMMING",
+	KEYCODE_HELP:                          "HELP",
+	KEYCODE_NAVIGATE_PREVIOUS:             "NAVIGATE_PREVIOUS",
+	KEYCODE_NAVIGATE_NEXT:                 "NAVIGATE_NEXT",
+	KEYCODE_NAVIGATE_IN:                   "NAVIGATE_IN",
+	KEYCODE_NAVIGATE_OUT:                  "NAVIGATE_OUT",
+	KEYCODE_STEM_PRIMARY:                  "STEM_PRIMARY",
+	KEYCODE_STEM_1:                        "STEM_1",
+	KEYCODE_STEM_2:                        "STEM_2",
+	KEYCODE_STEM_3:                        "STEM_3",
+	KEYCODE_DPAD_UP_LEFT:                  "DPAD_UP_LEFT",
+	KEYCODE_DPAD_DOWN_LEFT:                "DPAD_DOWN_LEFT",
+	KEYCODE_DPAD_UP_RIGHT:                 "DPAD_UP_RIGHT",
+	KEYCODE_DPAD_DOWN_RIGHT:               "DPAD_DOWN_RIGHT",
+	KEYCODE_MEDIA_SKIP_FORWARD:            "MEDIA_SKIP_FORWARD",
+	KEYCODE_MEDIA_SKIP_BACKWARD:           "MEDIA_SKIP_BACKWARD",
+	KEYCODE_MEDIA_STEP_FORWARD:            "MEDIA_STEP_FORWARD",
+	KEYCODE_MEDIA_STEP_BACKWARD:           "MEDIA_STEP_BACKWARD",
+	KEYCODE_SOFT_SLEEP:                    "SOFT_SLEEP",
+	KEYCODE_CUT:                           "CUT",
+	KEYCODE_COPY:                          "COPY",
+	KEYCODE_PASTE:                         "PASTE",
+	KEYCODE_SYSTEM_NAVIGATION_UP:          "SYSTEM_NAVIGATION_UP",
+	KEYCODE_SYSTEM_NAVIGATION_DOWN:        "SYSTEM_NAVIGATION_DOWN",
+	KEYCODE_SYSTEM_NAVIGATION_LEFT:        "SYSTEM_NAVIGATION_LEFT",
+	KEYCODE_SYSTEM_NAVIGATION_RIGHT:       "SYSTEM_NAVIGATION_RIGHT",
+	KEYCODE_ALL_APPS:                      "ALL_APPS",
+	KEYCODE_REFRESH:                       "REFRESH",
+	KEYCODE_THUMBS_UP:                     "THUMBS_UP",
+	KEYCODE_THUMBS_DOWN:                   "THUMBS_DOWN",
+	KEYCODE_PROFILE_SWITCH:                "PROFILE_SWITCH",
+	KEYCODE_VIDEO_APP_1:                   "VIDEO_APP_1",
+	KEYCODE_VIDEO_APP_2:                   "VIDEO_APP_2",
+	KEYCODE_VIDEO_APP_3:                   "VIDEO_APP_3",
+	KEYCODE_VIDEO_APP_4:                   "VIDEO_APP_4",
+	KEYCODE_VIDEO_APP_5:                   "VIDEO_APP_5",
+	KEYCODE_VIDEO_APP_6:                   "VIDEO_APP_6",
+	KEYCODE_VIDEO_APP_7:                   "VIDEO_APP_7",
+	KEYCODE_VIDEO_APP_8:                   "VIDEO_APP_8",
+	KEYCODE_FEATURED_APP_1:                "FEATURED_APP_1",
+	KEYCODE_FEATURED_APP_2:                "FEATURED_APP_2",
+	KEYCODE_FEATURED_APP_3:                "FEATURED_APP_3",
+	KEYCODE_FEATURED_APP_4:                "FEATURED_APP_4",
+	KEYCODE_DEMO_APP_1:                    "DEMO_APP_1",
+	KEYCODE_DEMO_APP_2:                    "DEMO_APP_2",
+	KEYCODE_DEMO_APP_3:                    "DEMO_APP_3",
+	KEYCODE_DEMO_APP_4:                    "DEMO_APP_4",
+	KEYCODE_KEYBOARD_BACKLIGHT_DOWN:       "KEYBOARD_BACKLIGHT_DOWN",
+	KEYCODE_KEYBOARD_BACKLIGHT_UP:         "KEYBOARD_BACKLIGHT_UP",
+	KEYCODE_KEYBOARD_BACKLIGHT_TOGGLE:     "KEYBOARD_BACKLIGHT_TOGGLE",
+	KEYCODE_STYLUS_BUTTON_PRIMARY:         "STYLUS_BUTTON_PRIMARY",
+	KEYCODE_STYLUS_BUTTON_SECONDARY:       "STYLUS_BUTTON_SECONDARY",
+	KEYCODE_STYLUS_BUTTON_TERTIARY:        "STYLUS_BUTTON_TERTIARY",
+	KEYCODE_STYLUS_BUTTON_TAIL:            "STYLUS_BUTTON_TAIL",
+	KEYCODE_RECENT_APPS:                   "RECENT_APPS",
+	KEYCODE_MACRO_1:                       "MACRO_1",
+	KEYCODE_MACRO_2:                       "MACRO_2",
+	KEYCODE_MACRO_3:                       "MACRO_3",
+	KEYCODE_MACRO_4:                       "MACRO_4",
+}
+
+func (key Key) String() string {
+	if name, ok := _KEY_NAMES[key]; ok {
+		return "KEYCODE_" + name
+	}
+	return "KEYCODE_UNDEFINED"
+}
+
+/*
+Check the key is in the KEYCODE_* table
+*/
+func (key Key) Valid() bool {
+	_, ok := _KEY_NAMES[key]
+	return ok && key != KEYCODE_UNKNOWN
+}
+
+/*
+Check the meta state only contains the META_* flags
+*/
+func (meta MetaState) Valid() bool {
+	return meta&^META_MASK == 0
+}
@@ -0,0 +1,257 @@
+/**
+Device time, timezone and locale
+*/
+package uiautomator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// https://play.google.com/store/apps/details?id=net.sanapeli.adbchangelanguage
+	LOCALE_HELPER_PACKAGE  = "net.sanapeli.adbchangelanguage"
+	LOCALE_HELPER_ACTIVITY = LOCALE_HELPER_PACKAGE + "/.AdbChangeLanguage"
+)
+
+var (
+	AUTO_TIME_SETTINGS = []*SettingKey{
+		{SETTINGS_GLOBAL, "auto_time"},
+		{SETTINGS_GLOBAL, "auto_time_zone"},
+	}
+)
+
+type (
+	TimeLocaleSnapshot struct {
+		ua       *UIAutomator
+		settings *SettingsSnapshot
+		timezone string
+		locale   string
+	}
+)
+
+/*
+Get the device time in the device timezone
+*/
+func (ua *UIAutomator) GetDeviceTime() (time.Time, error) {
+	output, err := ua.Shell([]string{"date", "+%s"}, 5)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t := time.Unix(seconds, 0)
+
+	// The tzdata may be not available on the host
+	if timezone, err := ua.GetTimezone(); err == nil && timezone != "" {
+		if location, err := time.LoadLocation(timezone); err == nil {
+			t = t.In(location)
+		}
+	}
+
+	return t, nil
+}
+
+/*
+Get the device timezone, e.g. "Asia/Shanghai"
+*/
+func (ua *UIAutomator) GetTimezone() (string, error) {
+	return ua.GetProp("persist.sys.timezone")
+}
+
+/*
+Set the device timezone, e.g. "Asia/Shanghai"
+*/
+func (ua *UIAutomator) SetTimezone(timezone string) error {
+	if timezone == "" {
+		return fmt.Errorf("SetTimezone: timezone can not be empty")
+	}
+
+	// Android 12+
+	result, err := ua.RunShell(
+		[]string{"cmd", "alarm", "set-timezone", timezone},
+		&ShellOptions{Timeout: 5, AllowFailure: true, Quote: true},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.ExitCode != 0 || strings.Contains(result.Output, "Unknown") {
+		// IAlarmManager.setTimeZone
+		if _, err := ua.shellArgs([]string{"service", "call", "alarm", "3", "s16", timezone}, 5); err != nil {
+			return err
+		}
+	}
+
+	current, err := ua.GetTimezone()
+	if err != nil {
+		return err
+	}
+
+	if current != timezone {
+		return fmt.Errorf("SetTimezone: failed to set %q, current is %q", timezone, current)
+	}
+
+	return nil
+}
+
+/*
+Turn on or off the automatic time and timezone
+*/
+func (ua *UIAutomator) SetAutoTime(enable bool) error {
+	value := 0
+	if enable {
+		value = 1
+	}
+
+	for _, key := range AUTO_TIME_SETTINGS {
+		if err := ua.Settings(key.Namespace).Put(key.Name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+Get the system locale, e.g. "en-US"
+*/
+func (ua *UIAutomator) GetLocale() (string, error) {
+	props, err := ua.GetProps()
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range []string{"persist.sys.locale", "ro.product.locale"} {
+		if locale := props[name]; locale != "" {
+			return locale, nil
+		}
+	}
+
+	// Android 4.x
+	language, country := props["persist.sys.language"], props["persist.sys.country"]
+	if language == "" {
+		language, country = props["ro.product.locale.language"], props["ro.product.locale.region"]
+	}
+
+	if country == "" {
+		return language, nil
+	}
+	return language + "-" + country, nil
+}
+
+/*
+Change the system locale, e.g. "zh-CN".
+The shell can not change the locale itself, the helper app LOCALE_HELPER_PACKAGE
+is required (with CHANGE_CONFIGURATION granted), the locale is verified after changed
+*/
+func (ua *UIAutomator) SetLocale(locale string) error {
+	if locale == "" {
+		return fmt.Errorf("SetLocale: locale can not be empty")
+	}
+
+	result, err := ua.RunShell(
+		[]string{"pm", "path", LOCALE_HELPER_PACKAGE},
+		&ShellOptions{Timeout: 5, AllowFailure: true, Quote: true},
+	)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(result.Output, "package:") {
+		return fmt.Errorf("SetLocale: the helper app %s is required", LOCALE_HELPER_PACKAGE)
+	}
+
+	if _, err := ua.shellArgs([]string{"am", "start", "-W", "-n", LOCALE_HELPER_ACTIVITY, "-e", "language", locale}, 10); err != nil {
+		return err
+	}
+
+	var current string
+	for i := 0; i < 3; i++ {
+		if current, err = ua.GetLocale(); err == nil && strings.EqualFold(current, locale) {
+			return nil
+		}
+
+		time.Sleep(time.Duration(500) * time.Millisecond)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf(
+		"SetLocale: locale is %q after changed, the helper app %s requires CHANGE_CONFIGURATION granted",
+		current,
+		LOCALE_HELPER_PACKAGE,
+	)
+}
+
+/*
+Take a snapshot of the timezone, automatic time and locale
+*/
+func (ua *UIAutomator) SnapshotTimeLocale() (*TimeLocaleSnapshot, error) {
+	settings, err := ua.SnapshotSettings(AUTO_TIME_SETTINGS...)
+	if err != nil {
+		return nil, err
+	}
+
+	timezone, err := ua.GetTimezone()
+	if err != nil {
+		return nil, err
+	}
+
+	locale, err := ua.GetLocale()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TimeLocaleSnapshot{
+		ua:       ua,
+		settings: settings,
+		timezone: timezone,
+		locale:   locale,
+	}, nil
+}
+
+/*
+Restore the timezone, automatic time and locale, only the changed ones are set
+*/
+func (snapshot *TimeLocaleSnapshot) Restore() error {
+	// Restore the timezone before the automatic time, it may be updated by the network
+	if snapshot.timezone != "" {
+		timezone, err := snapshot.ua.GetTimezone()
+		if err != nil {
+			return err
+		}
+
+		if timezone != snapshot.timezone {
+			if err := snapshot.ua.SetTimezone(snapshot.timezone); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := snapshot.settings.Restore(); err != nil {
+		return err
+	}
+
+	if snapshot.locale != "" {
+		locale, err := snapshot.ua.GetLocale()
+		if err != nil {
+			return err
+		}
+
+		// SetLocale requires the helper app, skip it if not changed
+		if !strings.EqualFold(locale, snapshot.locale) {
+			return snapshot.ua.SetLocale(snapshot.locale)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+/**
+Screen lock handling
+*/
+package uiautomator
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	_LOCK_PATTERN_VIEW = ".*:id/lockPatternView"
+)
+
+var _KEYGUARD_REGEXP = regexp.MustCompile(`(?m)^\s*(?:mShowingLockscreen|isStatusBarKeyguard|mKeyguardShowing|showing)=(true|false)`)
+
+type (
+	UnlockOptions struct {
+		PIN      string // e.g. "1234"
+		Password string
+		Pattern  []int // Cells of the 3x3 grid, 1 is the top left, 9 is the bottom right
+	}
+)
+
+/*
+Parse the keyguard state from "dumpsys window policy"
+*/
+func parseKeyguardShowing(output string) (showing bool, ok bool) {
+	matched := _KEYGUARD_REGEXP.FindStringSubmatch(output)
+	if matched == nil {
+		return false, false
+	}
+
+	return matched[1] == "true", true
+}
+
+/*
+Check the keyguard is showing
+*/
+func (ua *UIAutomator) IsLocked() (bool, error) {
+	output, err := ua.Shell([]string{"dumpsys", "window", "policy"}, 10)
+	if err != nil {
+		return false, err
+	}
+
+	if showing, ok := parseKeyguardShowing(output); ok {
+		return showing, nil
+	}
+
+	// Android 10+ moved the state to the activity manager
+	output, err = ua.Shell([]string{"dumpsys", "activity", "activities"}, 10)
+	if err != nil {
+		return false, err
+	}
+
+	if showing, ok := parseKeyguardShowing(output); ok {
+		return showing, nil
+	}
+
+	return false, fmt.Errorf("IsLocked: unknown keyguard state")
+}
+
+/*
+Unlock the device, enter the PIN, password or pattern if the keyguard is secure
+*/
+func (ua *UIAutomator) UnlockWith(options *UnlockOptions) error {
+	if options == nil {
+		options = &UnlockOptions{}
+	}
+
+	if err := ua.WakeUp(); err != nil {
+		return err
+	}
+
+	locked, err := ua.IsLocked()
+	if err != nil || !locked {
+		return err
+	}
+
+	// Dismiss the lock screen
+	if err := ua.Swipe(RelPoint{X: 0.5, Y: 0.8}, RelPoint{X: 0.5, Y: 0.2}, 10); err != nil {
+		return err
+	}
+	time.Sleep(time.Duration(500) * time.Millisecond)
+
+	if locked, err = ua.IsLocked(); err != nil || !locked {
+		return err
+	}
+
+	switch {
+	case options.PIN != "":
+		err = ua.enterCredential(options.PIN)
+	case options.Password != "":
+		err = ua.enterCredential(options.Password)
+	case len(options.Pattern) > 0:
+		err = ua.enterPattern(options.Pattern)
+	default:
+		return fmt.Errorf("UnlockWith: the keyguard is secure, credential is required")
+	}
+
+	if err != nil {
+		return err
+	}
+	time.Sleep(time.Duration(1000) * time.Millisecond)
+
+	if locked, err = ua.IsLocked(); err != nil {
+		return err
+	}
+
+	if locked {
+		return fmt.Errorf("UnlockWith: the device remains locked")
+	}
+
+	return nil
+}
+
+func (ua *UIAutomator) enterCredential(credential string) error {
+	if _, err := ua.shellArgs([]string{"input", "text", credential}, 10); err != nil {
+		return err
+	}
+
+	return ua.PressKeyCode(KEYCODE_ENTER, 0)
+}
+
+func (ua *UIAutomator) enterPattern(pattern []int) error {
+	visited := make(map[int]bool)
+	for _, cell := range pattern {
+		if cell < 1 || cell > 9 || visited[cell] {
+			return fmt.Errorf("UnlockWith: invalid pattern %v", pattern)
+		}
+		visited[cell] = true
+	}
+
+	// Skip the app guard, the keyguard is never the allowed app
+	view := ua.GetElementBySelector(Selector{"resourceIdMatches": _LOCK_PATTERN_VIEW})
+	if err := view.wait(0.5, 3, true); err != nil {
+		return err
+	}
+
+	rect, err := view.GetRect()
+	if err != nil {
+		return err
+	}
+
+	// Center of the cells
+	width := float32(rect.Right-rect.Left) / 3
+	height := float32(rect.Bottom-rect.Top) / 3
+	points := make([]Coordinate, 0, len(pattern))
+
+	for _, cell := range pattern {
+		row, col := (cell-1)/3, (cell-1)%3
+		points = append(points, pointOf(
+			float32(rect.Left)+width*(float32(col)+0.5),
+			float32(rect.Top)+height*(float32(row)+0.5),
+		))
+	}
+
+	return ua.SwipePoints(points...)
+}
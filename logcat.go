@@ -0,0 +1,326 @@
+/**
+Capture the device logs
+*/
+package uiautomator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LOG_VERBOSE LogLevel = "V"
+	LOG_DEBUG   LogLevel = "D"
+	LOG_INFO    LogLevel = "I"
+	LOG_WARN    LogLevel = "W"
+	LOG_ERROR   LogLevel = "E"
+	LOG_FATAL   LogLevel = "F"
+
+	LOGCAT_BUFFER_SIZE = 10000 // Default entries kept in the buffer
+)
+
+var (
+	_LOG_LEVELS = map[LogLevel]int{
+		LOG_VERBOSE: 2,
+		LOG_DEBUG:   3,
+		LOG_INFO:    4,
+		LOG_WARN:    5,
+		LOG_ERROR:   6,
+		LOG_FATAL:   7,
+		"A":         7, // Assert
+	}
+
+	// Format: "-v threadtime"
+	_LOGCAT_REGEXP = regexp.MustCompile(`^(\d\d-\d\d \d\d:\d\d:\d\d\.\d{3})\s+(\d+)\s+(\d+)\s+([VDIWEFA])\s+(.*?)\s*: (.*)$`)
+)
+
+type (
+	LogLevel string
+
+	LogEntry struct {
+		Time    time.Time
+		Pid     int
+		Tid     int
+		Level   LogLevel
+		Tag     string
+		Message string
+	}
+
+	LogcatOptions struct {
+		Tags    []string        // Only the tags, empty is all
+		Level   LogLevel        // Minimum level, default is verbose
+		Package string          // Only the processes of the package
+		Clear   bool            // Clear the device logs before start
+		Buffer  int             // Max entries kept
+		OnEntry func(*LogEntry) // Called for every entry matched
+	}
+
+	Logcat struct {
+		ua      *UIAutomator
+		options *LogcatOptions
+		entries []*LogEntry
+		pids    map[int]bool
+		mutex   sync.Mutex
+		cancel  context.CancelFunc
+		done    chan error
+	}
+)
+
+func (entry *LogEntry) String() string {
+	return fmt.Sprintf(
+		"%s %5d %5d %s %s: %s",
+		entry.Time.Format("01-02 15:04:05.000"),
+		entry.Pid,
+		entry.Tid,
+		entry.Level,
+		entry.Tag,
+		entry.Message,
+	)
+}
+
+/*
+Parse a line of "logcat -v threadtime"
+*/
+func parseLogEntry(line string) *LogEntry {
+	matched := _LOGCAT_REGEXP.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if matched == nil {
+		return nil
+	}
+
+	now := time.Now()
+	t, err := time.ParseInLocation("01-02 15:04:05.000", matched[1], time.Local)
+	if err != nil {
+		return nil
+	}
+
+	// The year is not in the output
+	t = t.AddDate(now.Year(), 0, 0)
+	if t.After(now.AddDate(0, 1, 0)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+
+	entry := &LogEntry{
+		Time:    t,
+		Level:   LogLevel(matched[4]),
+		Tag:     matched[5],
+		Message: matched[6],
+	}
+	entry.Pid, _ = strconv.Atoi(matched[2])
+	entry.Tid, _ = strconv.Atoi(matched[3])
+
+	return entry
+}
+
+/*
+Create a logcat capture
+*/
+func (ua *UIAutomator) NewLogcat(options *LogcatOptions) *Logcat {
+	if options == nil {
+		options = &LogcatOptions{}
+	}
+
+	if _, ok := _LOG_LEVELS[options.Level]; !ok {
+		options.Level = LOG_VERBOSE
+	}
+
+	if options.Buffer <= 0 {
+		options.Buffer = LOGCAT_BUFFER_SIZE
+	}
+
+	return &Logcat{
+		ua:      ua,
+		options: options,
+	}
+}
+
+func (logcat *Logcat) command() string {
+	command := []string{"logcat", "-v", "threadtime"}
+
+	if len(logcat.options.Tags) == 0 {
+		command = append(command, shellQuote("*:"+string(logcat.options.Level)))
+	} else {
+		for _, tag := range logcat.options.Tags {
+			command = append(command, shellQuote(tag+":"+string(logcat.options.Level)))
+		}
+		command = append(command, shellQuote("*:S"))
+	}
+
+	return strings.Join(command, " ")
+}
+
+/*
+Start capturing in the background
+*/
+func (logcat *Logcat) Start() error {
+	if logcat.cancel != nil {
+		return fmt.Errorf("Logcat: already started")
+	}
+
+	if logcat.options.Clear {
+		if _, err := logcat.ua.Shell([]string{"logcat", "-c"}, 10); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := logcat.ua.ShellStream(ctx, logcat.command())
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	logcat.cancel = cancel
+	logcat.done = make(chan error, 1)
+
+	go func() {
+		defer stream.Close()
+		logcat.done <- logcat.read(ctx, stream)
+	}()
+
+	return nil
+}
+
+func (logcat *Logcat) read(ctx context.Context, stream io.Reader) error {
+	var refreshed time.Time
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		entry := parseLogEntry(scanner.Text())
+		if entry == nil {
+			continue
+		}
+
+		// The app may be restarted, refresh the pids
+		if logcat.options.Package != "" && time.Since(refreshed) > 2*time.Second {
+			logcat.refreshPids()
+			refreshed = time.Now()
+		}
+
+		if !logcat.match(entry) {
+			continue
+		}
+
+		logcat.mutex.Lock()
+		logcat.entries = append(logcat.entries, entry)
+		if len(logcat.entries) > logcat.options.Buffer {
+			logcat.entries = logcat.entries[len(logcat.entries)-logcat.options.Buffer:]
+		}
+		logcat.mutex.Unlock()
+
+		if logcat.options.OnEntry != nil {
+			logcat.options.OnEntry(entry)
+		}
+	}
+
+	// Stopped by the user
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return scanner.Err()
+}
+
+func (logcat *Logcat) refreshPids() {
+	output, err := logcat.ua.Shell([]string{"pidof", logcat.options.Package}, 5)
+	if err != nil {
+		return
+	}
+
+	pids := make(map[int]bool)
+	for _, field := range strings.Fields(output) {
+		if pid, err := strconv.Atoi(field); err == nil {
+			pids[pid] = true
+		}
+	}
+
+	logcat.mutex.Lock()
+	logcat.pids = pids
+	logcat.mutex.Unlock()
+}
+
+func (logcat *Logcat) match(entry *LogEntry) bool {
+	if _LOG_LEVELS[entry.Level] < _LOG_LEVELS[logcat.options.Level] {
+		return false
+	}
+
+	if len(logcat.options.Tags) > 0 {
+		matched := false
+		for _, tag := range logcat.options.Tags {
+			if entry.Tag == tag {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	if logcat.options.Package != "" {
+		logcat.mutex.Lock()
+		defer logcat.mutex.Unlock()
+
+		return logcat.pids[entry.Pid]
+	}
+
+	return true
+}
+
+/*
+Stop capturing, the entries are kept
+*/
+func (logcat *Logcat) Stop() error {
+	if logcat.cancel == nil {
+		return nil
+	}
+
+	logcat.cancel()
+	err := <-logcat.done
+
+	logcat.cancel = nil
+	return err
+}
+
+/*
+Get the entries captured
+*/
+func (logcat *Logcat) Entries() []*LogEntry {
+	logcat.mutex.Lock()
+	defer logcat.mutex.Unlock()
+
+	entries := make([]*LogEntry, len(logcat.entries))
+	copy(entries, logcat.entries)
+	return entries
+}
+
+/*
+Clear the entries captured
+*/
+func (logcat *Logcat) Reset() {
+	logcat.mutex.Lock()
+	logcat.entries = nil
+	logcat.mutex.Unlock()
+}
+
+/*
+Dump the entries captured, e.g. when the test is failed
+*/
+func (logcat *Logcat) Dump(writer io.Writer) error {
+	for _, entry := range logcat.Entries() {
+		if _, err := fmt.Fprintln(writer, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
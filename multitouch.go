@@ -0,0 +1,424 @@
+/**
+Multi-touch gestures, every pointer is a timeline of down/move/pause/up steps
+*/
+package uiautomator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	GESTURE_MOVE_INTERVAL = 10 * time.Millisecond // Interval of the interpolated moves
+
+	_UIAUTOMATOR_STEP   = 5 * time.Millisecond // Duration of a step of uiautomator
+	_MINITOUCH_PATH     = "/minitouch"
+	_MINITOUCH_PRESSURE = 0.5
+)
+
+const (
+	_GESTURE_DOWN = iota
+	_GESTURE_MOVE
+	_GESTURE_PAUSE
+	_GESTURE_UP
+)
+
+type (
+	GestureBuilder struct {
+		ua       *UIAutomator
+		pointers []*PointerTimeline
+	}
+
+	PointerTimeline struct {
+		steps []*gestureStep
+	}
+
+	gestureStep struct {
+		action   int
+		position Coordinate
+		duration time.Duration
+	}
+
+	// A compiled event, the position is absolute
+	gestureEvent struct {
+		at       time.Duration
+		pointer  int
+		action   int
+		position *Position
+	}
+
+	minitouchRequest struct {
+		Operation    string  `json:"operation"` // d, m, u, c, w, r
+		Index        int     `json:"index"`
+		PercentX     float64 `json:"xP"`
+		PercentY     float64 `json:"yP"`
+		Milliseconds int     `json:"milliseconds"`
+		Pressure     float64 `json:"pressure"`
+	}
+)
+
+/*
+Create a gesture builder
+*/
+func (ua *UIAutomator) NewGesture() *GestureBuilder {
+	return &GestureBuilder{ua: ua}
+}
+
+/*
+Add a pointer, the pointers are performed simultaneously
+*/
+func (gesture *GestureBuilder) Pointer() *PointerTimeline {
+	pointer := &PointerTimeline{}
+	gesture.pointers = append(gesture.pointers, pointer)
+	return pointer
+}
+
+/*
+Touch down at the position
+*/
+func (pointer *PointerTimeline) Down(position Coordinate) *PointerTimeline {
+	pointer.steps = append(pointer.steps, &gestureStep{action: _GESTURE_DOWN, position: position})
+	return pointer
+}
+
+/*
+Move to the position in the duration
+*/
+func (pointer *PointerTimeline) MoveTo(position Coordinate, duration time.Duration) *PointerTimeline {
+	pointer.steps = append(pointer.steps, &gestureStep{action: _GESTURE_MOVE, position: position, duration: duration})
+	return pointer
+}
+
+/*
+Keep the pointer still, before down it delays the pointer
+*/
+func (pointer *PointerTimeline) Pause(duration time.Duration) *PointerTimeline {
+	pointer.steps = append(pointer.steps, &gestureStep{action: _GESTURE_PAUSE, duration: duration})
+	return pointer
+}
+
+/*
+Touch up
+*/
+func (pointer *PointerTimeline) Up() *PointerTimeline {
+	pointer.steps = append(pointer.steps, &gestureStep{action: _GESTURE_UP})
+	return pointer
+}
+
+/*
+Compile the timelines to the events sorted by time
+*/
+func (gesture *GestureBuilder) compile() ([]*gestureEvent, error) {
+	var events []*gestureEvent
+
+	for index, pointer := range gesture.pointers {
+		var (
+			at      time.Duration
+			current *Position
+			down    bool
+		)
+
+		for _, step := range pointer.steps {
+			switch step.action {
+			case _GESTURE_DOWN:
+				if down {
+					return nil, fmt.Errorf("Gesture: pointer %d is already down", index)
+				}
+				position, err := gesture.ua.toAbs(step.position)
+				if err != nil {
+					return nil, fmt.Errorf("Gesture: pointer %d, %s", index, err)
+				}
+				current, down = position, true
+				events = append(events, &gestureEvent{at, index, _GESTURE_DOWN, current})
+
+			case _GESTURE_MOVE:
+				if !down {
+					return nil, fmt.Errorf("Gesture: pointer %d moves before down", index)
+				}
+
+				target, err := gesture.ua.toAbs(step.position)
+				if err != nil {
+					return nil, fmt.Errorf("Gesture: pointer %d, %s", index, err)
+				}
+				count := int(step.duration / GESTURE_MOVE_INTERVAL)
+				if count < 1 {
+					count = 1
+				}
+
+				for i := 1; i <= count; i++ {
+					progress := float32(i) / float32(count)
+					events = append(events, &gestureEvent{
+						at + step.duration*time.Duration(i)/time.Duration(count),
+						index,
+						_GESTURE_MOVE,
+						&Position{
+							X: current.X + (target.X-current.X)*progress,
+							Y: current.Y + (target.Y-current.Y)*progress,
+						},
+					})
+				}
+				at += step.duration
+				current = target
+
+			case _GESTURE_PAUSE:
+				at += step.duration
+
+			case _GESTURE_UP:
+				if !down {
+					return nil, fmt.Errorf("Gesture: pointer %d is not down", index)
+				}
+				down = false
+				events = append(events, &gestureEvent{at, index, _GESTURE_UP, current})
+			}
+		}
+
+		if down {
+			return nil, fmt.Errorf("Gesture: pointer %d is not up", index)
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("Gesture: no pointer")
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].at < events[j].at
+	})
+
+	return events, nil
+}
+
+/*
+Perform the gesture, the single pointer is injected via injectInputEvent,
+the multiple pointers via minitouch of atx-agent
+*/
+func (gesture *GestureBuilder) Perform() error {
+	events, err := gesture.compile()
+	if err != nil {
+		return err
+	}
+
+	if len(gesture.pointers) == 1 {
+		return gesture.ua.injectEvents(events)
+	}
+
+	return gesture.ua.minitouch(events)
+}
+
+func (ua *UIAutomator) injectEvents(events []*gestureEvent) error {
+	started := time.Now()
+
+	for _, event := range events {
+		if wait := event.at - time.Since(started); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		var err error
+		switch event.action {
+		case _GESTURE_DOWN:
+			err = ua.touchDown(event.position)
+		case _GESTURE_MOVE:
+			err = ua.touchMove(event.position)
+		case _GESTURE_UP:
+			err = ua.touchUp(event.position)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ua *UIAutomator) minitouch(events []*gestureEvent) error {
+	size, err := ua.windowSize()
+	if err != nil {
+		return err
+	}
+
+	ws, err := ua.dialWebsocket(_MINITOUCH_PATH)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	for _, request := range minitouchRequests(events, size) {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+
+		if err := ws.WriteText(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+Convert the events to the minitouch requests, the events at the same time are committed together
+*/
+func minitouchRequests(events []*gestureEvent, size *WindowSize) []*minitouchRequest {
+	var (
+		requests []*minitouchRequest
+		at       time.Duration
+	)
+
+	for i, event := range events {
+		// Wait before the event, including the delay before the first one
+		if event.at > at {
+			if i > 0 {
+				requests = append(requests, &minitouchRequest{Operation: "c"})
+			}
+
+			milliseconds := int((event.at - at) / time.Millisecond)
+			requests = append(requests, &minitouchRequest{Operation: "w", Milliseconds: milliseconds})
+		}
+		at = event.at
+
+		request := &minitouchRequest{
+			Index:    event.pointer,
+			PercentX: clampPercent(float64(event.position.X) / float64(size.Width)),
+			PercentY: clampPercent(float64(event.position.Y) / float64(size.Height)),
+			Pressure: _MINITOUCH_PRESSURE,
+		}
+
+		switch event.action {
+		case _GESTURE_DOWN:
+			request.Operation = "d"
+		case _GESTURE_MOVE:
+			request.Operation = "m"
+		case _GESTURE_UP:
+			request.Operation = "u"
+		}
+
+		requests = append(requests, request)
+	}
+
+	return append(requests, &minitouchRequest{Operation: "c"})
+}
+
+/*
+Clamp the percent to [0, 1], the gesture near the edges may be out of the screen
+*/
+func clampPercent(percent float64) float64 {
+	return math.Max(0, math.Min(1, percent))
+}
+
+/*
+Pinch with two fingers around the center, the distance from the center
+changes from "from" to "to" in pixels, zoom in if to > from
+*/
+func (ua *UIAutomator) Pinch(center Coordinate, from float32, to float32, duration time.Duration) error {
+	abs, err := ua.toAbs(center)
+	if err != nil {
+		return fmt.Errorf("Pinch: %s", err)
+	}
+	gesture := ua.NewGesture()
+
+	for _, direction := range []float32{-1, 1} {
+		gesture.Pointer().
+			Down(pointOf(abs.X+from*direction, abs.Y)).
+			MoveTo(pointOf(abs.X+to*direction, abs.Y), duration).
+			Up()
+	}
+
+	return gesture.Perform()
+}
+
+/*
+Pinch in(zoom out) by coordinates, for the views not accessible.
+The fingers move from radius to the center by percent, a step is about 5ms
+*/
+func (ua *UIAutomator) PinchIn(center Coordinate, radius float32, percent int, steps int) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("PinchIn: invalid percent %d", percent)
+	}
+
+	return ua.Pinch(center, radius, radius*(1-float32(percent)/100), _UIAUTOMATOR_STEP*time.Duration(steps))
+}
+
+/*
+Pinch out(zoom in) by coordinates, for the views not accessible.
+The fingers move from the center to radius by percent, a step is about 5ms
+*/
+func (ua *UIAutomator) PinchOut(center Coordinate, radius float32, percent int, steps int) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("PinchOut: invalid percent %d", percent)
+	}
+
+	return ua.Pinch(center, radius*(1-float32(percent)/100), radius, _UIAUTOMATOR_STEP*time.Duration(steps))
+}
+
+/*
+Rotate with two fingers around the center, clockwise if degrees > 0
+*/
+func (ua *UIAutomator) Rotate(center Coordinate, radius float32, degrees float64, duration time.Duration) error {
+	abs, err := ua.toAbs(center)
+	if err != nil {
+		return fmt.Errorf("Rotate: %s", err)
+	}
+	gesture := ua.NewGesture()
+
+	// Approximate the arc with the segments of 5 degrees
+	segments := int(math.Ceil(math.Abs(degrees) / 5))
+	if segments < 1 {
+		segments = 1
+	}
+
+	for _, start := range []float64{0, 180} {
+		at := func(angle float64) Point {
+			radian := angle * math.Pi / 180
+			return pointOf(
+				abs.X+radius*float32(math.Cos(radian)),
+				abs.Y+radius*float32(math.Sin(radian)),
+			)
+		}
+
+		pointer := gesture.Pointer().Down(at(start))
+		for i := 1; i <= segments; i++ {
+			pointer.MoveTo(at(start+degrees*float64(i)/float64(segments)), duration/time.Duration(segments))
+		}
+		pointer.Up()
+	}
+
+	return gesture.Perform()
+}
+
+/*
+Swipe with two fingers side by side, spacing is the distance between the fingers in pixels
+*/
+func (ua *UIAutomator) TwoFingerSwipe(start Coordinate, end Coordinate, spacing float32, duration time.Duration) error {
+	from, err := ua.toAbs(start)
+	if err != nil {
+		return fmt.Errorf("TwoFingerSwipe: invalid start, %s", err)
+	}
+
+	to, err := ua.toAbs(end)
+	if err != nil {
+		return fmt.Errorf("TwoFingerSwipe: invalid end, %s", err)
+	}
+
+	// Place the fingers perpendicular to the direction
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := float32(math.Hypot(float64(dx), float64(dy)))
+	if length == 0 {
+		return fmt.Errorf("TwoFingerSwipe: from and to are the same")
+	}
+	ox, oy := -dy/length*spacing/2, dx/length*spacing/2
+
+	gesture := ua.NewGesture()
+	for _, direction := range []float32{-1, 1} {
+		gesture.Pointer().
+			Down(pointOf(from.X+ox*direction, from.Y+oy*direction)).
+			MoveTo(pointOf(to.X+ox*direction, to.Y+oy*direction), duration).
+			Up()
+	}
+
+	return gesture.Perform()
+}
@@ -0,0 +1,113 @@
+package uiautomator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGestureCompile(t *testing.T) {
+	ua := &UIAutomator{display: &displayCache{}}
+	ua.cacheWindowSize(&DeviceInfo{DisplayWidth: 1000, DisplayHeight: 2000})
+
+	gesture := ua.NewGesture()
+	gesture.Pointer().
+		Down(Point{X: 100, Y: 100}).
+		MoveTo(Point{X: 300, Y: 500}, 20*time.Millisecond).
+		Up()
+	gesture.Pointer().
+		Pause(5 * time.Millisecond).
+		Down(RelPoint{X: 0.5, Y: 0.5}).
+		Up()
+
+	events, err := gesture.compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []gestureEvent{
+		{0, 0, _GESTURE_DOWN, &Position{X: 100, Y: 100}},
+		{5 * time.Millisecond, 1, _GESTURE_DOWN, &Position{X: 500, Y: 1000}},
+		{5 * time.Millisecond, 1, _GESTURE_UP, &Position{X: 500, Y: 1000}},
+		{10 * time.Millisecond, 0, _GESTURE_MOVE, &Position{X: 200, Y: 300}},
+		{20 * time.Millisecond, 0, _GESTURE_MOVE, &Position{X: 300, Y: 500}},
+		{20 * time.Millisecond, 0, _GESTURE_UP, &Position{X: 300, Y: 500}},
+	}
+
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+
+	for i, event := range events {
+		if event.at != want[i].at || event.pointer != want[i].pointer ||
+			event.action != want[i].action || *event.position != *want[i].position {
+			t.Errorf("event %d: expected %+v %v, got %+v %v", i, want[i], want[i].position, *event, event.position)
+		}
+	}
+}
+
+func TestGestureCompileInvalid(t *testing.T) {
+	ua := &UIAutomator{display: &displayCache{}}
+
+	cases := []struct {
+		name  string
+		build func(*GestureBuilder)
+	}{
+		{"no pointer", func(gesture *GestureBuilder) {}},
+		{"move before down", func(gesture *GestureBuilder) {
+			gesture.Pointer().MoveTo(Point{X: 1, Y: 1}, time.Millisecond).Up()
+		}},
+		{"down twice", func(gesture *GestureBuilder) {
+			gesture.Pointer().Down(Point{X: 1, Y: 1}).Down(Point{X: 2, Y: 2}).Up()
+		}},
+		{"up before down", func(gesture *GestureBuilder) {
+			gesture.Pointer().Up()
+		}},
+		{"not up", func(gesture *GestureBuilder) {
+			gesture.Pointer().Down(Point{X: 1, Y: 1})
+		}},
+		{"invalid position", func(gesture *GestureBuilder) {
+			gesture.Pointer().Down(Point{X: -1, Y: 1}).Up()
+		}},
+	}
+
+	for _, c := range cases {
+		gesture := ua.NewGesture()
+		c.build(gesture)
+
+		if _, err := gesture.compile(); err == nil {
+			t.Errorf("%s: expected error", c.name)
+		}
+	}
+}
+
+func TestMinitouchRequests(t *testing.T) {
+	size := &WindowSize{Width: 1000, Height: 2000}
+	events := []*gestureEvent{
+		{10 * time.Millisecond, 0, _GESTURE_DOWN, &Position{X: 500, Y: 1000}},
+		{10 * time.Millisecond, 1, _GESTURE_DOWN, &Position{X: -50, Y: 2100}},
+		{30 * time.Millisecond, 0, _GESTURE_UP, &Position{X: 500, Y: 1000}},
+		{30 * time.Millisecond, 1, _GESTURE_UP, &Position{X: 0, Y: 0}},
+	}
+
+	want := []minitouchRequest{
+		{Operation: "w", Milliseconds: 10},
+		{Operation: "d", Index: 0, PercentX: 0.5, PercentY: 0.5, Pressure: _MINITOUCH_PRESSURE},
+		{Operation: "d", Index: 1, PercentX: 0, PercentY: 1, Pressure: _MINITOUCH_PRESSURE},
+		{Operation: "c"},
+		{Operation: "w", Milliseconds: 20},
+		{Operation: "u", Index: 0, PercentX: 0.5, PercentY: 0.5, Pressure: _MINITOUCH_PRESSURE},
+		{Operation: "u", Index: 1, PercentX: 0, PercentY: 0, Pressure: _MINITOUCH_PRESSURE},
+		{Operation: "c"},
+	}
+
+	requests := minitouchRequests(events, size)
+	if len(requests) != len(want) {
+		t.Fatalf("expected %d requests, got %d", len(want), len(requests))
+	}
+
+	for i, request := range requests {
+		if *request != want[i] {
+			t.Errorf("request %d: expected %+v, got %+v", i, want[i], *request)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+/**
+Network condition control, e.g. Wi-Fi, mobile data and airplane mode
+*/
+package uiautomator
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var (
+	_ACTIVE_NETWORK_REGEXP = regexp.MustCompile(`Active default network:\s*(\w+)`)
+)
+
+type (
+	NetworkState struct {
+		Wifi       bool
+		MobileData bool
+		Airplane   bool
+	}
+
+	Connectivity struct {
+		Connected bool
+		Transport string // e.g. WIFI, CELLULAR, empty if unknown
+	}
+)
+
+func enableOrDisable(enable bool) string {
+	if enable {
+		return "enable"
+	}
+	return "disable"
+}
+
+/*
+Turn on or off the Wi-Fi
+*/
+func (ua *UIAutomator) SetWifi(enable bool) error {
+	_, err := ua.Shell([]string{"svc", "wifi", enableOrDisable(enable)}, 10)
+	return err
+}
+
+/*
+Turn on or off the mobile data
+*/
+func (ua *UIAutomator) SetMobileData(enable bool) error {
+	_, err := ua.Shell([]string{"svc", "data", enableOrDisable(enable)}, 10)
+	return err
+}
+
+/*
+Turn on or off the airplane mode
+*/
+func (ua *UIAutomator) SetAirplaneMode(enable bool) error {
+	// Android 11+
+	result, err := ua.RunShell(
+		[]string{"cmd", "connectivity", "airplane-mode", enableOrDisable(enable)},
+		&ShellOptions{Timeout: 10, AllowFailure: true},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.ExitCode == 0 && result.Output == "" {
+		return nil
+	}
+
+	state := 0
+	if enable {
+		state = 1
+	}
+
+	if err := ua.Settings(SETTINGS_GLOBAL).Put("airplane_mode_on", state); err != nil {
+		return err
+	}
+
+	_, err = ua.Shell(
+		[]string{"am", "broadcast", "-a", "android.intent.action.AIRPLANE_MODE", "--ez", "state", strconv.FormatBool(enable)},
+		10,
+	)
+	return err
+}
+
+/*
+Get the current network state
+*/
+func (ua *UIAutomator) GetNetworkState() (*NetworkState, error) {
+	settings := ua.Settings(SETTINGS_GLOBAL)
+	state := &NetworkState{}
+
+	for name, value := range map[string]*bool{
+		"wifi_on":          &state.Wifi,
+		"mobile_data":      &state.MobileData,
+		"airplane_mode_on": &state.Airplane,
+	} {
+		setting, err := settings.GetInt(name, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		// wifi_on is 2 if it is on in the airplane mode
+		*value = setting != 0
+	}
+
+	return state, nil
+}
+
+/*
+Apply the network state
+*/
+func (ua *UIAutomator) SetNetworkState(state *NetworkState) error {
+	if err := ua.SetAirplaneMode(state.Airplane); err != nil {
+		return err
+	}
+
+	if err := ua.SetWifi(state.Wifi); err != nil {
+		return err
+	}
+
+	return ua.SetMobileData(state.MobileData)
+}
+
+/*
+Apply the network state during the function, the original state is restored afterwards
+*/
+func (ua *UIAutomator) WithNetworkState(state *NetworkState, fn func() error) (err error) {
+	original, err := ua.GetNetworkState()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if restoreErr := ua.SetNetworkState(original); err == nil {
+			err = restoreErr
+		}
+	}()
+
+	if err = ua.SetNetworkState(state); err != nil {
+		return
+	}
+
+	return fn()
+}
+
+/*
+Get the current connectivity
+*/
+func (ua *UIAutomator) GetConnectivity() (*Connectivity, error) {
+	output, err := ua.Shell([]string{"dumpsys", "connectivity"}, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseConnectivity(output), nil
+}
+
+/*
+Parse the active network from "dumpsys connectivity"
+*/
+func parseConnectivity(output string) *Connectivity {
+	connectivity := &Connectivity{}
+
+	matched := _ACTIVE_NETWORK_REGEXP.FindStringSubmatch(output)
+	if matched == nil || matched[1] == "none" {
+		return connectivity
+	}
+	connectivity.Connected = true
+
+	// The transport of the active network
+	network := regexp.MustCompile(`(?m)^.*NetworkAgentInfo\s*\{\s*network\{` + regexp.QuoteMeta(matched[1]) + `\}.*$`)
+	transport := regexp.MustCompile(`(?:Transports:\s*|type:\s*)(\w+)`)
+
+	if line := network.FindString(output); line != "" {
+		if matched := transport.FindStringSubmatch(line); matched != nil {
+			connectivity.Transport = matched[1]
+		}
+	}
+
+	return connectivity
+}
@@ -0,0 +1,400 @@
+/**
+Performance sampler of the app, e.g. CPU, memory and FPS
+*/
+package uiautomator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	PERF_INTERVAL = time.Second // Default sampling interval
+)
+
+var (
+	_PSS_REGEXP          = regexp.MustCompile(`(?m)^\s*TOTAL(?: PSS:)?\s+(\d+)`)
+	_FRAMES_REGEXP       = regexp.MustCompile(`Total frames rendered:\s*(\d+)`)
+	_JANKY_FRAMES_REGEXP = regexp.MustCompile(`Janky frames:\s*(\d+)`)
+)
+
+type (
+	PerfSample struct {
+		Time        time.Time `json:"time"`
+		CPU         float64   `json:"cpu"` // Percent of the whole device
+		PSS         int64     `json:"pss"` // KB
+		FPS         float64   `json:"fps"`
+		Frames      int       `json:"frames"`
+		JankyFrames int       `json:"jankyFrames"`
+	}
+
+	PerfSummary struct {
+		Samples     int     `json:"samples"`
+		AvgCPU      float64 `json:"avgCpu"`
+		P90CPU      float64 `json:"p90Cpu"`
+		MaxCPU      float64 `json:"maxCpu"`
+		AvgPSS      int64   `json:"avgPss"`
+		P90PSS      int64   `json:"p90Pss"`
+		MaxPSS      int64   `json:"maxPss"`
+		AvgFPS      float64 `json:"avgFps"`
+		P90FPS      float64 `json:"p90Fps"`
+		JankPercent float64 `json:"jankPercent"`
+	}
+
+	Perf struct {
+		ua       *UIAutomator
+		pkg      string
+		interval time.Duration
+		samples  []*PerfSample
+		mutex    sync.Mutex
+		stopped  chan bool
+		done     chan bool
+
+		// Previous state to calculate the deltas
+		pid      int
+		cpuTotal int64
+		cpuProc  int64
+		sampled  time.Time
+	}
+)
+
+/*
+Create a performance sampler of the package
+*/
+func (ua *UIAutomator) NewPerf(packageName string, interval time.Duration) *Perf {
+	if interval <= 0 {
+		interval = PERF_INTERVAL
+	}
+
+	return &Perf{
+		ua:       ua,
+		pkg:      packageName,
+		interval: interval,
+	}
+}
+
+/*
+Start sampling in the background
+*/
+func (perf *Perf) Start() error {
+	if perf.stopped != nil {
+		return fmt.Errorf("Perf: already started")
+	}
+
+	// Reset the frame stats, the frames are counted since now
+	if _, err := perf.ua.Shell([]string{"dumpsys", "gfxinfo", perf.pkg, "reset"}, 10); err != nil {
+		return err
+	}
+
+	perf.pid, perf.cpuTotal, perf.cpuProc = 0, 0, 0
+	perf.sampled = time.Now()
+
+	// Prime the cpu baseline, the app may be not running yet
+	perf.sampleCPU()
+
+	perf.stopped = make(chan bool)
+	perf.done = make(chan bool)
+
+	go func() {
+		defer close(perf.done)
+
+		ticker := time.NewTicker(perf.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-perf.stopped:
+				return
+			case <-ticker.C:
+				if sample, err := perf.sample(); err == nil && sample != nil {
+					perf.mutex.Lock()
+					perf.samples = append(perf.samples, sample)
+					perf.mutex.Unlock()
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+/*
+Stop sampling, the samples are kept
+*/
+func (perf *Perf) Stop() {
+	if perf.stopped == nil {
+		return
+	}
+
+	close(perf.stopped)
+	<-perf.done
+	perf.stopped = nil
+}
+
+/*
+Get the samples
+*/
+func (perf *Perf) Samples() []*PerfSample {
+	perf.mutex.Lock()
+	defer perf.mutex.Unlock()
+
+	samples := make([]*PerfSample, len(perf.samples))
+	copy(samples, perf.samples)
+	return samples
+}
+
+/*
+Take a sample, returns nil without the cpu baseline, e.g. the app is (re)started
+*/
+func (perf *Perf) sample() (*PerfSample, error) {
+	now := time.Now()
+	sample := &PerfSample{Time: now}
+
+	cpu, ok, err := perf.sampleCPU()
+	if err != nil || !ok {
+		return nil, err
+	}
+	sample.CPU = cpu
+
+	output, err := perf.ua.Shell([]string{"dumpsys", "meminfo", perf.pkg}, 10)
+	if err != nil {
+		return nil, err
+	}
+	sample.PSS = parsePSS(output)
+
+	// Print the stats since the last reset and reset again
+	output, err = perf.ua.Shell([]string{"dumpsys", "gfxinfo", perf.pkg, "reset"}, 10)
+	if err != nil {
+		return nil, err
+	}
+	sample.Frames, sample.JankyFrames = parseFrames(output)
+
+	if elapsed := now.Sub(perf.sampled).Seconds(); elapsed > 0 {
+		sample.FPS = float64(sample.Frames) / elapsed
+	}
+	perf.sampled = now
+
+	return sample, nil
+}
+
+/*
+Returns the cpu usage since the last reading, ok is false if there is no previous reading
+*/
+func (perf *Perf) sampleCPU() (cpu float64, ok bool, err error) {
+	// The app may be restarted
+	if perf.pid == 0 {
+		output, err := perf.ua.Shell([]string{"pidof", perf.pkg}, 5)
+		if err != nil {
+			return 0, false, err
+		}
+
+		fields := strings.Fields(output)
+		if len(fields) == 0 {
+			return 0, false, fmt.Errorf("Perf: %q is not running", perf.pkg)
+		}
+
+		perf.pid, _ = strconv.Atoi(fields[0])
+		perf.cpuTotal, perf.cpuProc = 0, 0
+	}
+
+	output, err := perf.ua.Shell([]string{"cat", "/proc/stat", fmt.Sprintf("/proc/%d/stat", perf.pid)}, 5)
+	if err != nil {
+		perf.pid = 0
+		return 0, false, err
+	}
+
+	total, proc, err := parseCPUStat(output)
+	if err != nil {
+		perf.pid = 0
+		return 0, false, err
+	}
+
+	if perf.cpuTotal > 0 && total > perf.cpuTotal {
+		cpu = float64(proc-perf.cpuProc) / float64(total-perf.cpuTotal) * 100
+		ok = true
+	}
+	perf.cpuTotal, perf.cpuProc = total, proc
+
+	return cpu, ok, nil
+}
+
+/*
+Parse the total jiffies from /proc/stat and the process jiffies from /proc/<pid>/stat
+*/
+func parseCPUStat(output string) (total int64, proc int64, err error) {
+	var foundTotal, foundProc bool
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) > 1 && fields[0] == "cpu" {
+			for _, field := range fields[1:] {
+				jiffies, _ := strconv.ParseInt(field, 10, 64)
+				total += jiffies
+			}
+			foundTotal = true
+			continue
+		}
+
+		// The comm may contain spaces, e.g. "123 (com.example) S ..."
+		index := strings.LastIndex(line, ")")
+		if index == -1 {
+			continue
+		}
+
+		fields = strings.Fields(line[index+1:])
+		if len(fields) < 13 {
+			continue
+		}
+
+		utime, _ := strconv.ParseInt(fields[11], 10, 64)
+		stime, _ := strconv.ParseInt(fields[12], 10, 64)
+		proc = utime + stime
+		foundProc = true
+	}
+
+	if !foundTotal || !foundProc {
+		err = fmt.Errorf("Perf: invalid cpu stat %q", output)
+	}
+	return
+}
+
+/*
+Parse the total PSS(KB) from "dumpsys meminfo <package>"
+*/
+func parsePSS(output string) int64 {
+	matched := _PSS_REGEXP.FindStringSubmatch(output)
+	if matched == nil {
+		return 0
+	}
+
+	pss, _ := strconv.ParseInt(matched[1], 10, 64)
+	return pss
+}
+
+/*
+Parse the frames from "dumpsys gfxinfo <package>"
+*/
+func parseFrames(output string) (frames int, janky int) {
+	if matched := _FRAMES_REGEXP.FindStringSubmatch(output); matched != nil {
+		frames, _ = strconv.Atoi(matched[1])
+	}
+
+	if matched := _JANKY_FRAMES_REGEXP.FindStringSubmatch(output); matched != nil {
+		janky, _ = strconv.Atoi(matched[1])
+	}
+	return
+}
+
+/*
+Get the summary statistics of the samples
+*/
+func (perf *Perf) Summary() *PerfSummary {
+	samples := perf.Samples()
+	summary := &PerfSummary{Samples: len(samples)}
+
+	if len(samples) == 0 {
+		return summary
+	}
+
+	var (
+		cpus, pss, fps []float64
+		frames, janky  int
+	)
+
+	for _, sample := range samples {
+		cpus = append(cpus, sample.CPU)
+		pss = append(pss, float64(sample.PSS))
+		fps = append(fps, sample.FPS)
+		frames += sample.Frames
+		janky += sample.JankyFrames
+	}
+
+	summary.AvgCPU, summary.P90CPU, summary.MaxCPU = stats(cpus)
+	avgPSS, p90PSS, maxPSS := stats(pss)
+	summary.AvgPSS, summary.P90PSS, summary.MaxPSS = int64(avgPSS), int64(p90PSS), int64(maxPSS)
+	summary.AvgFPS, summary.P90FPS, _ = stats(fps)
+
+	if frames > 0 {
+		summary.JankPercent = float64(janky) / float64(frames) * 100
+	}
+
+	return summary
+}
+
+/*
+Calculate the average, 90th percentile(nearest rank) and max
+*/
+func stats(values []float64) (avg float64, p90 float64, max float64) {
+	if len(values) == 0 {
+		return
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	for _, value := range sorted {
+		avg += value
+	}
+	avg /= float64(len(sorted))
+
+	rank := int(math.Ceil(0.9*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	p90 = sorted[rank]
+	max = sorted[len(sorted)-1]
+	return
+}
+
+/*
+Export the samples as CSV
+*/
+func (perf *Perf) WriteCSV(writer io.Writer) error {
+	w := csv.NewWriter(writer)
+
+	if err := w.Write([]string{"time", "cpu", "pss", "fps", "frames", "janky_frames"}); err != nil {
+		return err
+	}
+
+	for _, sample := range perf.Samples() {
+		if err := w.Write([]string{
+			sample.Time.Format(time.RFC3339Nano),
+			strconv.FormatFloat(sample.CPU, 'f', 2, 64),
+			strconv.FormatInt(sample.PSS, 10),
+			strconv.FormatFloat(sample.FPS, 'f', 2, 64),
+			strconv.Itoa(sample.Frames),
+			strconv.Itoa(sample.JankyFrames),
+		}); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+/*
+Export the samples and summary as JSON
+*/
+func (perf *Perf) WriteJSON(writer io.Writer) error {
+	return json.NewEncoder(writer).Encode(struct {
+		Package string        `json:"package"`
+		Samples []*PerfSample `json:"samples"`
+		Summary *PerfSummary  `json:"summary"`
+	}{
+		Package: perf.pkg,
+		Samples: perf.Samples(),
+		Summary: perf.Summary(),
+	})
+}
@@ -0,0 +1,211 @@
+/**
+Screen recording via screenrecord
+*/
+package uiautomator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RECORD_BIT_RATE      = 4000000 // Default bit rate(bps)
+	RECORD_SEGMENT_LIMIT = 180     // The time limit of screenrecord(second)
+	RECORD_REMOTE_DIR    = "/sdcard"
+
+	// The segment ended earlier is failed or stopped, the next segment is not chained
+	_RECORD_SEGMENT_MIN = (RECORD_SEGMENT_LIMIT - 10) * time.Second
+)
+
+type (
+	RecordOptions struct {
+		BitRate   int    // Bit rate(bps), default is 4Mbps
+		Width     int    // Video width, default is the display width
+		Height    int    // Video height, default is the display height
+		OutputDir string // Local directory of the pulled files, default is the temp directory
+	}
+
+	Recorder struct {
+		ua       *UIAutomator
+		options  *RecordOptions
+		prefix   string
+		segments []string
+		pid      int       // The pid of the running screenrecord
+		started  time.Time // The running segment started
+		mutex    sync.Mutex
+		cancel   context.CancelFunc
+		stopping bool
+		done     chan error
+	}
+)
+
+/*
+Start recording the screen in the background.
+screenrecord is limited to 3 minutes, the longer recording is chained by segments
+*/
+func (ua *UIAutomator) StartRecording(options *RecordOptions) (*Recorder, error) {
+	if options == nil {
+		options = &RecordOptions{}
+	}
+
+	if options.BitRate <= 0 {
+		options.BitRate = RECORD_BIT_RATE
+	}
+
+	if options.OutputDir == "" {
+		options.OutputDir = os.TempDir()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := &Recorder{
+		ua:      ua,
+		options: options,
+		prefix:  fmt.Sprintf("uia-record-%d", time.Now().UnixNano()),
+		cancel:  cancel,
+		done:    make(chan error, 1),
+	}
+
+	// Make sure the first segment is started
+	recorder.mutex.Lock()
+	stream, err := recorder.startSegment(ctx)
+	recorder.mutex.Unlock()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go func() {
+		recorder.done <- recorder.record(ctx, stream)
+	}()
+
+	return recorder, nil
+}
+
+/*
+Start the next segment, the caller holds the mutex
+*/
+func (recorder *Recorder) startSegment(ctx context.Context) (io.ReadCloser, error) {
+	remotePath := path.Join(RECORD_REMOTE_DIR, fmt.Sprintf("%s-%d.mp4", recorder.prefix, len(recorder.segments)))
+
+	// Exec, so the pid of the stream is the pid of screenrecord
+	command := []string{
+		"exec", "screenrecord",
+		"--bit-rate", fmt.Sprint(recorder.options.BitRate),
+		"--time-limit", fmt.Sprint(RECORD_SEGMENT_LIMIT),
+	}
+	if recorder.options.Width > 0 && recorder.options.Height > 0 {
+		command = append(command, "--size", fmt.Sprintf("%dx%d", recorder.options.Width, recorder.options.Height))
+	}
+	command = append(command, remotePath)
+
+	stream, err := recorder.ua.ShellStream(ctx, strings.Join(command, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	recorder.segments = append(recorder.segments, remotePath)
+	recorder.pid = stream.(*shellStreamReader).pid
+	recorder.started = time.Now()
+
+	return stream, nil
+}
+
+func (recorder *Recorder) record(ctx context.Context, stream io.ReadCloser) error {
+	for {
+		// Wait the segment finished, screenrecord prints nothing unless failed
+		var output bytes.Buffer
+		_, err := io.Copy(&output, stream)
+		stream.Close()
+
+		recorder.mutex.Lock()
+		if recorder.stopping || ctx.Err() != nil {
+			recorder.mutex.Unlock()
+			return nil
+		}
+
+		// Chain only after the time limit reached, otherwise screenrecord is failed
+		if elapsed := time.Since(recorder.started); err == nil && elapsed < _RECORD_SEGMENT_MIN {
+			err = fmt.Errorf("Recorder: screenrecord exited after %s: %s", elapsed.Round(time.Second), strings.TrimSpace(output.String()))
+		}
+
+		if err == nil {
+			stream, err = recorder.startSegment(ctx)
+		}
+		recorder.mutex.Unlock()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+/*
+Stop recording, returns the local paths of the pulled MP4 files in the recorded order.
+Every segment is a separate file of 3 minutes at most, the files are not concatenated,
+e.g. join them by ffmpeg's concat demuxer if a single file is needed.
+If the recording is failed, the recorded segments are still pulled and returned with the error
+*/
+func (recorder *Recorder) Stop() ([]string, error) {
+	recorder.mutex.Lock()
+	if recorder.stopping {
+		recorder.mutex.Unlock()
+		return nil, fmt.Errorf("Recorder: already stopped")
+	}
+	recorder.stopping = true
+	pid := recorder.pid
+	segments := recorder.segments
+	recorder.mutex.Unlock()
+
+	defer recorder.cancel()
+
+	// screenrecord finalizes the file on SIGINT, the stream is ended after that
+	if _, err := recorder.ua.RunShell(
+		[]string{"kill", "-INT", strconv.Itoa(pid)},
+		&ShellOptions{Timeout: 5, AllowFailure: true},
+	); err != nil {
+		return nil, err
+	}
+
+	var recordErr error
+	select {
+	case recordErr = <-recorder.done:
+	case <-time.After(10 * time.Second):
+		recordErr = fmt.Errorf("Recorder: screenrecord not exited")
+	}
+
+	paths := make([]string, 0, len(segments))
+	for _, remotePath := range segments {
+		// The failed segment may be not created or empty
+		if size, err := recorder.remoteSize(remotePath); err != nil || size == 0 {
+			continue
+		}
+
+		localPath := filepath.Join(recorder.options.OutputDir, path.Base(remotePath))
+		if err := recorder.ua.pullFile(remotePath, localPath, nil); err != nil {
+			return paths, err
+		}
+		paths = append(paths, localPath)
+
+		recorder.ua.RunShell([]string{"rm", "-f", remotePath}, &ShellOptions{Timeout: 5, AllowFailure: true, Quote: true})
+	}
+
+	return paths, recordErr
+}
+
+func (recorder *Recorder) remoteSize(remotePath string) (int64, error) {
+	output, err := recorder.ua.shellArgs([]string{"stat", "-c", "%s", remotePath}, 5)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+}
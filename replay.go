@@ -0,0 +1,421 @@
+/**
+Record the gestures from the device input events and replay them
+*/
+package uiautomator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	_GETEVENT_DEVICE_REGEXP = regexp.MustCompile(`^add device \d+:\s+(\S+)`)
+	_GETEVENT_ABS_REGEXP    = regexp.MustCompile(`(ABS_MT_POSITION_[XY])\s*:\s*value -?\d+, min (-?\d+), max (-?\d+)`)
+	_GETEVENT_EVENT_REGEXP  = regexp.MustCompile(`^\[\s*(\d+)\.(\d+)\]\s+(?:\S+:\s+)?(\w+)\s+(\w+)\s+(\w+)`)
+)
+
+type (
+	GestureRecording struct {
+		Width  int             `json:"width"` // Window size when recorded
+		Height int             `json:"height"`
+		Tracks []*GestureTrack `json:"tracks"`
+	}
+
+	GestureTrack struct {
+		Pointer int           `json:"pointer"`
+		Points  []*TrackPoint `json:"points"` // The first point is down, the last is up
+	}
+
+	TrackPoint struct {
+		Time int64 `json:"t"` // Milliseconds since the recording started
+		X    int   `json:"x"`
+		Y    int   `json:"y"`
+	}
+
+	GestureRecorder struct {
+		ua        *UIAutomator
+		device    *touchDevice
+		rotation  int
+		recording *GestureRecording
+		cancel    context.CancelFunc
+		done      chan error
+	}
+
+	// The touch screen input device
+	touchDevice struct {
+		path       string
+		xMin, xMax int
+		yMin, yMax int
+	}
+
+	// Decodes the multi-touch protocol B
+	touchDecoder struct {
+		device  *touchDevice
+		convert func(x int, y int) (int, int)
+		started int64 // Microseconds of the first event
+		slot    int
+		slots   map[int]*touchSlot
+		tracks  []*GestureTrack
+	}
+
+	// The slot keeps the last position across the contacts, the unchanged value is not reported again
+	touchSlot struct {
+		track   *GestureTrack // nil if not in contact
+		x, y    int
+		changed bool
+		up      bool
+	}
+)
+
+/*
+Parse the touch screen from "getevent -lp", the device has ABS_MT_POSITION_X and ABS_MT_POSITION_Y
+*/
+func parseTouchDevice(output string) (*touchDevice, error) {
+	var device *touchDevice
+
+	for _, line := range strings.Split(output, "\n") {
+		if matched := _GETEVENT_DEVICE_REGEXP.FindStringSubmatch(strings.TrimSpace(line)); matched != nil {
+			if device != nil && device.xMax > device.xMin && device.yMax > device.yMin {
+				return device, nil
+			}
+			device = &touchDevice{path: matched[1]}
+			continue
+		}
+
+		matched := _GETEVENT_ABS_REGEXP.FindStringSubmatch(line)
+		if matched == nil || device == nil {
+			continue
+		}
+
+		min, _ := strconv.Atoi(matched[2])
+		max, _ := strconv.Atoi(matched[3])
+		if matched[1] == "ABS_MT_POSITION_X" {
+			device.xMin, device.xMax = min, max
+		} else {
+			device.yMin, device.yMax = min, max
+		}
+	}
+
+	if device != nil && device.xMax > device.xMin && device.yMax > device.yMin {
+		return device, nil
+	}
+
+	return nil, fmt.Errorf("Touch screen not found")
+}
+
+/*
+Convert the raw touch coordinate to the screen pixels.
+The touch screen is in the natural orientation, width and height are the natural size
+*/
+func (device *touchDevice) toScreen(x int, y int, width int, height int, rotation int) (int, int) {
+	nx := (x - device.xMin) * width / (device.xMax - device.xMin + 1)
+	ny := (y - device.yMin) * height / (device.yMax - device.yMin + 1)
+
+	switch rotation {
+	case 1:
+		return ny, width - 1 - nx
+	case 2:
+		return width - 1 - nx, height - 1 - ny
+	case 3:
+		return height - 1 - ny, nx
+	}
+
+	return nx, ny
+}
+
+func newTouchDecoder(device *touchDevice, convert func(int, int) (int, int)) *touchDecoder {
+	return &touchDecoder{
+		device:  device,
+		convert: convert,
+		started: -1,
+		slots:   make(map[int]*touchSlot),
+	}
+}
+
+/*
+Decode a line of "getevent -lt"
+*/
+func (decoder *touchDecoder) decode(line string) {
+	matched := _GETEVENT_EVENT_REGEXP.FindStringSubmatch(line)
+	if matched == nil {
+		return
+	}
+
+	seconds, _ := strconv.ParseInt(matched[1], 10, 64)
+	micros, _ := strconv.ParseInt((matched[2] + "000000")[:6], 10, 64)
+	timestamp := seconds*1000000 + micros
+
+	if decoder.started < 0 {
+		decoder.started = timestamp
+	}
+
+	// The value is hex, e.g. "ffffffff" is -1
+	raw, _ := strconv.ParseUint(matched[5], 16, 32)
+	value := int(int32(raw))
+
+	slot := decoder.slots[decoder.slot]
+	if slot == nil && strings.HasPrefix(matched[4], "ABS_MT_") && matched[4] != "ABS_MT_SLOT" {
+		slot = &touchSlot{}
+		decoder.slots[decoder.slot] = slot
+	}
+
+	switch matched[4] {
+	case "ABS_MT_SLOT":
+		decoder.slot = value
+
+	case "ABS_MT_TRACKING_ID":
+		if value == -1 {
+			if slot.track != nil {
+				slot.up, slot.changed = true, true
+			}
+			break
+		}
+
+		// The new contact starts at the last position of the slot
+		slot.track = &GestureTrack{Pointer: decoder.slot}
+		slot.up, slot.changed = false, true
+		decoder.tracks = append(decoder.tracks, slot.track)
+
+	case "ABS_MT_POSITION_X":
+		slot.x, slot.changed = value, slot.track != nil
+
+	case "ABS_MT_POSITION_Y":
+		slot.y, slot.changed = value, slot.track != nil
+
+	case "SYN_REPORT":
+		decoder.report((timestamp - decoder.started) / 1000)
+	}
+}
+
+func (decoder *touchDecoder) report(milliseconds int64) {
+	for _, slot := range decoder.slots {
+		if !slot.changed || slot.track == nil {
+			continue
+		}
+
+		x, y := decoder.convert(slot.x, slot.y)
+		slot.track.Points = append(slot.track.Points, &TrackPoint{Time: milliseconds, X: x, Y: y})
+		slot.changed = false
+
+		// Finish the track, the position is kept for the next contact
+		if slot.up {
+			slot.track, slot.up = nil, false
+		}
+	}
+}
+
+/*
+Start recording the gestures on the touch screen
+*/
+func (ua *UIAutomator) RecordGesture() (*GestureRecorder, error) {
+	output, err := ua.Shell([]string{"getevent", "-lp"}, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := parseTouchDevice(output)
+	if err != nil {
+		return nil, fmt.Errorf("RecordGesture: %s", err)
+	}
+
+	info, err := ua.GetDeviceInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	// The natural size of the display
+	width, height := info.DisplayWidth, info.DisplayHeight
+	if info.DisplayRotation%2 == 1 {
+		width, height = height, width
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := ua.ShellStream(ctx, "getevent -lt "+shellQuote(device.path))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	recorder := &GestureRecorder{
+		ua:       ua,
+		device:   device,
+		rotation: info.DisplayRotation,
+		recording: &GestureRecording{
+			Width:  info.DisplayWidth,
+			Height: info.DisplayHeight,
+		},
+		cancel: cancel,
+		done:   make(chan error, 1),
+	}
+
+	decoder := newTouchDecoder(device, func(x int, y int) (int, int) {
+		return device.toScreen(x, y, width, height, info.DisplayRotation)
+	})
+
+	go func() {
+		defer stream.Close()
+
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			decoder.decode(scanner.Text())
+		}
+
+		recorder.recording.Tracks = decoder.tracks
+		if ctx.Err() != nil {
+			recorder.done <- nil
+			return
+		}
+		recorder.done <- scanner.Err()
+	}()
+
+	return recorder, nil
+}
+
+/*
+Stop recording, returns the recorded gestures
+*/
+func (recorder *GestureRecorder) Stop() (*GestureRecording, error) {
+	recorder.cancel()
+
+	if err := <-recorder.done; err != nil {
+		return nil, err
+	}
+
+	// Drop the tracks not finished
+	tracks := make([]*GestureTrack, 0, len(recorder.recording.Tracks))
+	for _, track := range recorder.recording.Tracks {
+		if len(track.Points) > 0 {
+			tracks = append(tracks, track)
+		}
+	}
+	recorder.recording.Tracks = tracks
+
+	return recorder.recording, nil
+}
+
+/*
+Save the recording as JSON
+*/
+func (recording *GestureRecording) Save(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(recording)
+}
+
+/*
+Load the recording saved
+*/
+func LoadGesture(reader io.Reader) (*GestureRecording, error) {
+	recording := &GestureRecording{}
+	if err := json.NewDecoder(reader).Decode(recording); err != nil {
+		return nil, err
+	}
+
+	return recording, nil
+}
+
+/*
+Compile the recording to the events, scaled to the window size
+*/
+func (recording *GestureRecording) compile(size *WindowSize) (events []*gestureEvent, overlapped bool) {
+	scaleX, scaleY := float32(1), float32(1)
+	if recording.Width > 0 && recording.Height > 0 {
+		scaleX = float32(size.Width) / float32(recording.Width)
+		scaleY = float32(size.Height) / float32(recording.Height)
+	}
+
+	var lastUp int64 = -1
+	tracks := make([]*GestureTrack, 0, len(recording.Tracks))
+	for _, track := range recording.Tracks {
+		if len(track.Points) > 0 {
+			tracks = append(tracks, track)
+		}
+	}
+
+	sort.SliceStable(tracks, func(i, j int) bool {
+		return tracks[i].Points[0].Time < tracks[j].Points[0].Time
+	})
+
+	for _, track := range tracks {
+		first, last := track.Points[0], track.Points[len(track.Points)-1]
+		if first.Time <= lastUp {
+			overlapped = true
+		}
+		if last.Time > lastUp {
+			lastUp = last.Time
+		}
+
+		for i, point := range track.Points {
+			action := _GESTURE_MOVE
+			if i == 0 {
+				action = _GESTURE_DOWN
+			} else if i == len(track.Points)-1 {
+				action = _GESTURE_UP
+			}
+
+			events = append(events, &gestureEvent{
+				at:       time.Duration(point.Time) * time.Millisecond,
+				pointer:  track.Pointer,
+				action:   action,
+				position: &Position{X: float32(point.X) * scaleX, Y: float32(point.Y) * scaleY},
+			})
+		}
+
+		// A tap has only one point
+		if len(track.Points) == 1 {
+			events = append(events, &gestureEvent{
+				at:       time.Duration(first.Time) * time.Millisecond,
+				pointer:  track.Pointer,
+				action:   _GESTURE_UP,
+				position: events[len(events)-1].position,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].at < events[j].at
+	})
+
+	return
+}
+
+/*
+Replay the recording via the touch injection of gesture.go, scaled to the current window size.
+The injection has only one pointer, the recording with simultaneous pointers is replayed
+via the minitouch of atx-agent instead, it fails on the device without minitouch
+*/
+func (ua *UIAutomator) Replay(recording *GestureRecording) error {
+	// The recording is scaled to the current rotation
+	size, err := ua.refreshWindowSize()
+	if err != nil {
+		return err
+	}
+
+	events, overlapped := recording.compile(size)
+	if len(events) == 0 {
+		return fmt.Errorf("Replay: empty recording")
+	}
+
+	// Start from the first event
+	started := events[0].at
+	for _, event := range events {
+		event.at -= started
+	}
+
+	if overlapped {
+		if err := ua.minitouch(events); err != nil {
+			return fmt.Errorf("Replay: simultaneous pointers require minitouch, %s", err)
+		}
+		return nil
+	}
+
+	return ua.injectEvents(events)
+}
@@ -0,0 +1,193 @@
+package uiautomator
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	_GETEVENT_LP = `add device 1: /dev/input/event4
+  name:     "gpio-keys"
+  events:
+    KEY (0001): KEY_VOLUMEDOWN        KEY_VOLUMEUP          KEY_POWER
+  input props:
+    <none>
+add device 2: /dev/input/event2
+  name:     "synaptics_dsx"
+  events:
+    KEY (0001): KEY_WAKEUP            BTN_TOOL_FINGER       BTN_TOUCH
+    ABS (0003): ABS_MT_SLOT           : value 0, min 0, max 9, fuzz 0, flat 0, resolution 0
+                ABS_MT_TOUCH_MAJOR    : value 0, min 0, max 255, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_X     : value 0, min 0, max 1439, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_Y     : value 0, min 0, max 2559, fuzz 0, flat 0, resolution 0
+                ABS_MT_TRACKING_ID    : value 0, min 0, max 65535, fuzz 0, flat 0, resolution 0
+                ABS_MT_PRESSURE       : value 0, min 0, max 255, fuzz 0, flat 0, resolution 0
+  input props:
+    INPUT_PROP_DIRECT
+add device 3: /dev/input/event0
+  name:     "qpnp_pon"
+  events:
+    KEY (0001): KEY_VOLUMEDOWN        KEY_POWER
+  input props:
+    <none>
+`
+
+	// A tap, a two-finger gesture, then a tap with the unchanged X not reported
+	_GETEVENT_LT = `[  100.000000] EV_ABS       ABS_MT_SLOT          00000000
+[  100.000000] EV_ABS       ABS_MT_TRACKING_ID   00000001
+[  100.000000] EV_ABS       ABS_MT_POSITION_X    000002d0
+[  100.000000] EV_ABS       ABS_MT_POSITION_Y    00000500
+[  100.000000] EV_KEY       BTN_TOUCH            DOWN
+[  100.000000] EV_SYN       SYN_REPORT           00000000
+[  100.050000] EV_ABS       ABS_MT_TRACKING_ID   ffffffff
+[  100.050000] EV_KEY       BTN_TOUCH            UP
+[  100.050000] EV_SYN       SYN_REPORT           00000000
+[  101.000000] EV_ABS       ABS_MT_TRACKING_ID   00000002
+[  101.000000] EV_ABS       ABS_MT_POSITION_X    00000064
+[  101.000000] EV_ABS       ABS_MT_POSITION_Y    000000c8
+[  101.000000] EV_ABS       ABS_MT_SLOT          00000001
+[  101.000000] EV_ABS       ABS_MT_TRACKING_ID   00000003
+[  101.000000] EV_ABS       ABS_MT_POSITION_X    0000012c
+[  101.000000] EV_ABS       ABS_MT_POSITION_Y    00000190
+[  101.000000] EV_KEY       BTN_TOUCH            DOWN
+[  101.000000] EV_SYN       SYN_REPORT           00000000
+[  101.016000] EV_ABS       ABS_MT_SLOT          00000000
+[  101.016000] EV_ABS       ABS_MT_POSITION_X    0000006e
+[  101.016000] EV_ABS       ABS_MT_SLOT          00000001
+[  101.016000] EV_ABS       ABS_MT_POSITION_X    00000136
+[  101.016000] EV_SYN       SYN_REPORT           00000000
+[  101.032000] EV_ABS       ABS_MT_SLOT          00000000
+[  101.032000] EV_ABS       ABS_MT_TRACKING_ID   ffffffff
+[  101.032000] EV_ABS       ABS_MT_SLOT          00000001
+[  101.032000] EV_ABS       ABS_MT_TRACKING_ID   ffffffff
+[  101.032000] EV_KEY       BTN_TOUCH            UP
+[  101.032000] EV_SYN       SYN_REPORT           00000000
+[  102.000000] EV_ABS       ABS_MT_SLOT          00000000
+[  102.000000] EV_ABS       ABS_MT_TRACKING_ID   00000004
+[  102.000000] EV_ABS       ABS_MT_POSITION_Y    000000d2
+[  102.000000] EV_KEY       BTN_TOUCH            DOWN
+[  102.000000] EV_SYN       SYN_REPORT           00000000
+[  102.040000] EV_ABS       ABS_MT_TRACKING_ID   ffffffff
+[  102.040000] EV_KEY       BTN_TOUCH            UP
+[  102.040000] EV_SYN       SYN_REPORT           00000000
+`
+)
+
+func TestParseTouchDevice(t *testing.T) {
+	device, err := parseTouchDevice(_GETEVENT_LP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := touchDevice{path: "/dev/input/event2", xMin: 0, xMax: 1439, yMin: 0, yMax: 2559}
+	if *device != want {
+		t.Errorf("expected %+v, got %+v", want, *device)
+	}
+
+	if _, err := parseTouchDevice("add device 1: /dev/input/event4\n  name:     \"gpio-keys\"\n"); err == nil {
+		t.Error("expected error without touch screen")
+	}
+}
+
+func TestTouchDeviceToScreen(t *testing.T) {
+	device := &touchDevice{xMin: 0, xMax: 1439, yMin: 0, yMax: 2559}
+
+	cases := []struct {
+		rotation int
+		x, y     int
+	}{
+		{0, 100, 200},
+		{1, 200, 1339},
+		{2, 1339, 2359},
+		{3, 2359, 100},
+	}
+
+	for _, c := range cases {
+		x, y := device.toScreen(100, 200, 1440, 2560, c.rotation)
+		if x != c.x || y != c.y {
+			t.Errorf("rotation %d: expected %d, %d, got %d, %d", c.rotation, c.x, c.y, x, y)
+		}
+	}
+
+	// The raw range is scaled to the display
+	scaled := &touchDevice{xMin: 0, xMax: 4095, yMin: 0, yMax: 4095}
+	if x, y := scaled.toScreen(2048, 4095, 1080, 1920, 0); x != 540 || y != 1919 {
+		t.Errorf("expected 540, 1919, got %d, %d", x, y)
+	}
+}
+
+func TestTouchDecoder(t *testing.T) {
+	device := &touchDevice{xMin: 0, xMax: 1439, yMin: 0, yMax: 2559}
+	decoder := newTouchDecoder(device, func(x int, y int) (int, int) {
+		return device.toScreen(x, y, 1440, 2560, 0)
+	})
+
+	for _, line := range strings.Split(_GETEVENT_LT, "\n") {
+		decoder.decode(line)
+	}
+
+	want := []*GestureTrack{
+		{Pointer: 0, Points: []*TrackPoint{{0, 720, 1280}, {50, 720, 1280}}},
+		{Pointer: 0, Points: []*TrackPoint{{1000, 100, 200}, {1016, 110, 200}, {1032, 110, 200}}},
+		{Pointer: 1, Points: []*TrackPoint{{1000, 300, 400}, {1016, 310, 400}, {1032, 310, 400}}},
+		{Pointer: 0, Points: []*TrackPoint{{2000, 110, 210}, {2040, 110, 210}}},
+	}
+
+	if len(decoder.tracks) != len(want) {
+		t.Fatalf("expected %d tracks, got %d", len(want), len(decoder.tracks))
+	}
+
+	for i, track := range decoder.tracks {
+		if track.Pointer != want[i].Pointer || len(track.Points) != len(want[i].Points) {
+			t.Errorf("track %d: expected %+v, got %+v", i, want[i], track)
+			continue
+		}
+
+		for j, point := range track.Points {
+			if *point != *want[i].Points[j] {
+				t.Errorf("track %d point %d: expected %+v, got %+v", i, j, *want[i].Points[j], *point)
+			}
+		}
+	}
+
+	for index, slot := range decoder.slots {
+		if slot.track != nil {
+			t.Errorf("expected all the pointers up, slot %d is down", index)
+		}
+	}
+}
+
+func TestGestureRecordingCompile(t *testing.T) {
+	recording := &GestureRecording{
+		Width:  1440,
+		Height: 2560,
+		Tracks: []*GestureTrack{
+			{Pointer: 0, Points: []*TrackPoint{{0, 720, 1280}}},
+			{Pointer: 0, Points: []*TrackPoint{{100, 100, 200}, {200, 110, 200}}},
+		},
+	}
+
+	events, overlapped := recording.compile(&WindowSize{Width: 720, Height: 1280})
+	if overlapped {
+		t.Error("expected the sequential tracks")
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+
+	// The tap is down and up at the same point, scaled to the half
+	if events[0].action != _GESTURE_DOWN || events[1].action != _GESTURE_UP || *events[1].position != (Position{X: 360, Y: 640}) {
+		t.Errorf("unexpected tap events %+v %+v", events[0], events[1])
+	}
+
+	if *events[3].position != (Position{X: 55, Y: 100}) {
+		t.Errorf("expected 55, 100, got %v", events[3].position)
+	}
+
+	// Two fingers at the same time
+	recording.Tracks = append(recording.Tracks, &GestureTrack{Pointer: 1, Points: []*TrackPoint{{150, 300, 400}, {250, 310, 400}}})
+	if _, overlapped := recording.compile(&WindowSize{Width: 1440, Height: 2560}); !overlapped {
+		t.Error("expected the overlapped tracks")
+	}
+}
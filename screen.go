@@ -26,14 +26,18 @@ type (
 )
 
 func (ua *UIAutomator) setOrientation(orientation ORIENTATION) error {
-	return ua.post(
+	err := ua.post(
 		&RPCOptions{
 			Method: "setOrientation",
-			Params: []interface{}{},
+			Params: []interface{}{orientation},
 		},
 		nil,
 		nil,
 	)
+
+	// The window size is changed after rotated
+	ua.InvalidateWindowSize()
+	return err
 }
 
 /*
@@ -6,6 +6,7 @@ package uiautomator
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 )
@@ -214,6 +215,10 @@ func (ele Element) Eq(index int) *Element {
 Check if the specific UI object exists
 */
 func (ele Element) WaitForExists(duration float32, maxRetry int) error {
+	if err := ele.ua.checkAppGuard(); err != nil {
+		return err
+	}
+
 	if duration < 0 || duration > 60 {
 		duration = WAIT_FOR_EXISTS_DURATION
 	}
@@ -229,6 +234,10 @@ func (ele Element) WaitForExists(duration float32, maxRetry int) error {
 Wait the specific UI object disappear
 */
 func (ele Element) WaitUntilGone(duration float32, maxRetry int) error {
+	if err := ele.ua.checkAppGuard(); err != nil {
+		return err
+	}
+
 	if duration < 0 || duration > 60 {
 		duration = WAIT_FOR_DISAPPEAR_DURATION
 	}
@@ -321,26 +330,26 @@ func (ele Element) swipe(direction string) error {
 	switch direction {
 	case "up":
 		return ele.ua.Swipe(
-			&Position{X: float32(cx), Y: float32(cy)},
-			&Position{X: float32(cx), Y: float32(ly)},
+			Point{X: cx, Y: cy},
+			Point{X: cx, Y: ly},
 			20,
 		)
 	case "down":
 		return ele.ua.Swipe(
-			&Position{X: float32(cx), Y: float32(cy)},
-			&Position{X: float32(cx), Y: float32(ry - 1)},
+			Point{X: cx, Y: cy},
+			Point{X: cx, Y: ry - 1},
 			20,
 		)
 	case "left":
 		return ele.ua.Swipe(
-			&Position{X: float32(cx), Y: float32(cy)},
-			&Position{X: float32(lx), Y: float32(cy)},
+			Point{X: cx, Y: cy},
+			Point{X: lx, Y: cy},
 			20,
 		)
 	case "right":
 		return ele.ua.Swipe(
-			&Position{X: float32(cx), Y: float32(cy)},
-			&Position{X: float32(rx - 1), Y: float32(cy)},
+			Point{X: cx, Y: cy},
+			Point{X: rx - 1, Y: cy},
 			20,
 		)
 	}
@@ -385,22 +394,34 @@ func (ele *Element) Click(offset *Position) error {
 		return err
 	}
 
-	return ele.ClickNoWait(offset)
+	return ele.click(offset)
 }
 
 func (ele *Element) ClickNoWait(offset *Position) error {
+	if err := ele.ua.checkAppGuard(); err != nil {
+		return err
+	}
+
+	return ele.click(offset)
+}
+
+func (ele *Element) click(offset *Position) error {
 	abs, err := ele.Center(offset)
 	if err != nil {
 		return err
 	}
 
-	return ele.ua.Click(abs)
+	return ele.ua.Click(pointOf(abs.X, abs.Y))
 }
 
 /*
 Screen scroll up
 */
 func (ele *Element) ScrollUp(step int) error {
+	if err := ele.ua.checkAppGuard(); err != nil {
+		return err
+	}
+
 	if err := ele.ua.post(
 		&RPCOptions{
 			Method: "scrollForward",
@@ -419,6 +440,10 @@ func (ele *Element) ScrollUp(step int) error {
 Screen scroll down
 */
 func (ele *Element) ScrollDown(step int) error {
+	if err := ele.ua.checkAppGuard(); err != nil {
+		return err
+	}
+
 	if err := ele.ua.post(
 		&RPCOptions{
 			Method: "scrollBackward",
@@ -437,6 +462,10 @@ func (ele *Element) ScrollDown(step int) error {
 Screen scroll to beginning
 */
 func (ele *Element) ScrollToBeginning() error {
+	if err := ele.ua.checkAppGuard(); err != nil {
+		return err
+	}
+
 	if err := ele.ua.post(
 		&RPCOptions{
 			Method: "flingBackward",
@@ -455,6 +484,10 @@ func (ele *Element) ScrollToBeginning() error {
 Screen scroll to end
 */
 func (ele *Element) ScrollToEnd() error {
+	if err := ele.ua.checkAppGuard(); err != nil {
+		return err
+	}
+
 	if err := ele.ua.post(
 		&RPCOptions{
 			Method: "scrollToEnd",
@@ -473,6 +506,10 @@ func (ele *Element) ScrollToEnd() error {
 Screen scroll to selector
 */
 func (ele *Element) ScrollTo(selector Selector) error {
+	if err := ele.ua.checkAppGuard(); err != nil {
+		return err
+	}
+
 	selector = parseSelector(selector)
 
 	if err := ele.ua.post(
@@ -503,7 +540,89 @@ func (ele *Element) LongClick() error {
 		return err
 	}
 
-	return ele.ua.LongClick(abs, 0)
+	return ele.ua.LongClick(pointOf(abs.X, abs.Y), 0)
+}
+
+func (ele *Element) pinch(method string, percent int, steps int) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("%s: invalid percent %d", method, percent)
+	}
+
+	config := ele.ua.GetConfig()
+	if err := ele.WaitForExists(config.WaitForExistsDuration, config.WaitForExistsMaxRetry); err != nil {
+		return err
+	}
+
+	return ele.ua.post(
+		&RPCOptions{
+			Method: method,
+			Params: []interface{}{getParams(ele.selector), percent, steps},
+		},
+		nil,
+		nil,
+	)
+}
+
+/*
+Pinch in(zoom out) the element, the fingers move from the edges to the center by percent
+*/
+func (ele *Element) PinchIn(percent int, steps int) error {
+	return ele.pinch("pinchIn", percent, steps)
+}
+
+/*
+Pinch out(zoom in) the element, the fingers move from the center to the edges by percent
+*/
+func (ele *Element) PinchOut(percent int, steps int) error {
+	return ele.pinch("pinchOut", percent, steps)
+}
+
+/*
+Drag the element to the target in the duration(second),
+the target is an *Element, a Position or a Coordinate
+*/
+func (ele *Element) DragTo(target interface{}, duration float32) error {
+	config := ele.ua.GetConfig()
+	if err := ele.WaitForExists(config.WaitForExistsDuration, config.WaitForExistsMaxRetry); err != nil {
+		return err
+	}
+
+	// Same as Drag
+	steps := int(duration * 200)
+	params := []interface{}{getParams(ele.selector)}
+
+	// Only *Position implements Coordinate
+	if position, ok := target.(Position); ok {
+		target = &position
+	}
+
+	switch typed := target.(type) {
+	case *Element:
+		if typed == nil {
+			return fmt.Errorf("DragTo: target element can not be nil")
+		}
+		if err := typed.WaitForExists(config.WaitForExistsDuration, config.WaitForExistsMaxRetry); err != nil {
+			return err
+		}
+		params = append(params, getParams(typed.selector), steps)
+	case Coordinate:
+		abs, err := ele.ua.toAbs(typed)
+		if err != nil {
+			return fmt.Errorf("DragTo: %s", err)
+		}
+		params = append(params, int(abs.X), int(abs.Y), steps)
+	default:
+		return fmt.Errorf("DragTo: unsupported target %T", target)
+	}
+
+	return ele.ua.post(
+		&RPCOptions{
+			Method: "dragTo",
+			Params: params,
+		},
+		nil,
+		nil,
+	)
 }
 
 /*
@@ -0,0 +1,346 @@
+/**
+System properties and settings
+*/
+package uiautomator
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SETTINGS_SYSTEM SettingsNamespace = "system"
+	SETTINGS_SECURE SettingsNamespace = "secure"
+	SETTINGS_GLOBAL SettingsNamespace = "global"
+
+	LOCATION_MODE_OFF            = 0
+	LOCATION_MODE_SENSORS_ONLY   = 1
+	LOCATION_MODE_BATTERY_SAVING = 2
+	LOCATION_MODE_HIGH_ACCURACY  = 3
+
+	STAY_AWAKE_OFF      = 0
+	STAY_AWAKE_AC       = 1
+	STAY_AWAKE_USB      = 2
+	STAY_AWAKE_WIRELESS = 4
+	STAY_AWAKE_ALL      = STAY_AWAKE_AC | STAY_AWAKE_USB | STAY_AWAKE_WIRELESS
+)
+
+var (
+	_GETPROP_REGEXP = regexp.MustCompile(`^\[([^\]]+)\]:\s*\[(.*)\]$`)
+
+	// Settings of the animation scales
+	ANIMATION_SCALES = []*SettingKey{
+		{SETTINGS_GLOBAL, "window_animation_scale"},
+		{SETTINGS_GLOBAL, "transition_animation_scale"},
+		{SETTINGS_GLOBAL, "animator_duration_scale"},
+	}
+)
+
+type (
+	SettingsNamespace string
+
+	Settings struct {
+		ua        *UIAutomator
+		namespace SettingsNamespace
+	}
+
+	SettingKey struct {
+		Namespace SettingsNamespace
+		Name      string
+	}
+
+	SettingsSnapshot struct {
+		ua     *UIAutomator
+		keys   []*SettingKey
+		values []*string // nil if the setting is not exists
+	}
+)
+
+/*
+Get a system property
+*/
+func (ua *UIAutomator) GetProp(name string) (string, error) {
+	output, err := ua.shellArgs([]string{"getprop", name}, 5)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+/*
+Get all the system properties
+*/
+func (ua *UIAutomator) GetProps() (map[string]string, error) {
+	output, err := ua.Shell([]string{"getprop"}, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseProps(output), nil
+}
+
+/*
+Parse the output of getprop, e.g. "[ro.build.version.sdk]: [28]"
+*/
+func parseProps(output string) map[string]string {
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+
+	for scanner.Scan() {
+		matched := _GETPROP_REGEXP.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if matched != nil {
+			props[matched[1]] = matched[2]
+		}
+	}
+
+	return props
+}
+
+/*
+Get the settings of the namespace
+*/
+func (ua *UIAutomator) Settings(namespace SettingsNamespace) *Settings {
+	return &Settings{
+		ua:        ua,
+		namespace: namespace,
+	}
+}
+
+/*
+Get the setting, ok is false if the setting is not exists
+*/
+func (settings *Settings) Get(name string) (value string, ok bool, err error) {
+	output, err := settings.ua.shellArgs([]string{"settings", "get", string(settings.namespace), name}, 5)
+	if err != nil {
+		return
+	}
+
+	value = strings.TrimSpace(output)
+	if value == "null" {
+		return "", false, nil
+	}
+
+	return value, true, nil
+}
+
+/*
+Get the setting as int
+*/
+func (settings *Settings) GetInt(name string, fallback int) (int, error) {
+	value, ok, err := settings.Get(name)
+	if err != nil || !ok {
+		return fallback, err
+	}
+
+	return strconv.Atoi(value)
+}
+
+/*
+Get the setting as float
+*/
+func (settings *Settings) GetFloat(name string, fallback float32) (float32, error) {
+	value, ok, err := settings.Get(name)
+	if err != nil || !ok {
+		return fallback, err
+	}
+
+	parsed, err := strconv.ParseFloat(value, 32)
+	return float32(parsed), err
+}
+
+/*
+Put the setting
+*/
+func (settings *Settings) Put(name string, value interface{}) error {
+	_, err := settings.ua.shellArgs([]string{"settings", "put", string(settings.namespace), name, fmt.Sprint(value)}, 5)
+	return err
+}
+
+/*
+Delete the setting
+*/
+func (settings *Settings) Delete(name string) error {
+	_, err := settings.ua.shellArgs([]string{"settings", "delete", string(settings.namespace), name}, 5)
+	return err
+}
+
+/*
+Get the animation scales, in the order of ANIMATION_SCALES
+*/
+func (ua *UIAutomator) GetAnimationScales() ([]float32, error) {
+	scales := make([]float32, 0, len(ANIMATION_SCALES))
+
+	for _, key := range ANIMATION_SCALES {
+		scale, err := ua.Settings(key.Namespace).GetFloat(key.Name, 1)
+		if err != nil {
+			return nil, err
+		}
+		scales = append(scales, scale)
+	}
+
+	return scales, nil
+}
+
+/*
+Set all the animation scales, 0 is disabled
+*/
+func (ua *UIAutomator) SetAnimationScale(scale float32) error {
+	for _, key := range ANIMATION_SCALES {
+		if err := ua.Settings(key.Namespace).Put(key.Name, scale); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+Disable the animations for stable tests, the previous scales are remembered
+*/
+func (ua *UIAutomator) DisableAnimations() error {
+	// Keep the original snapshot when called twice
+	if ua.animations == nil {
+		snapshot, err := ua.SnapshotSettings(ANIMATION_SCALES...)
+		if err != nil {
+			return err
+		}
+		ua.animations = snapshot
+	}
+
+	if err := ua.SetAnimationScale(0); err != nil {
+		return err
+	}
+
+	ua.animErr = nil
+	return nil
+}
+
+/*
+Get the error of disabling the animations by Config.DisableAnimations,
+nil if succeeded or not configured. Call DisableAnimations to retry
+*/
+func (ua *UIAutomator) AnimationsError() error {
+	return ua.animErr
+}
+
+/*
+Restore the animation scales before DisableAnimations
+*/
+func (ua *UIAutomator) RestoreAnimations() error {
+	if ua.animations == nil {
+		return nil
+	}
+
+	if err := ua.animations.Restore(); err != nil {
+		return err
+	}
+
+	ua.animations = nil
+	return nil
+}
+
+/*
+Get the screen off timeout
+*/
+func (ua *UIAutomator) GetScreenOffTimeout() (time.Duration, error) {
+	timeout, err := ua.Settings(SETTINGS_SYSTEM).GetInt("screen_off_timeout", 0)
+	return time.Duration(timeout) * time.Millisecond, err
+}
+
+/*
+Set the screen off timeout
+*/
+func (ua *UIAutomator) SetScreenOffTimeout(timeout time.Duration) error {
+	return ua.Settings(SETTINGS_SYSTEM).Put("screen_off_timeout", int64(timeout/time.Millisecond))
+}
+
+/*
+Get the stay awake mode while plugged in, see STAY_AWAKE_*
+*/
+func (ua *UIAutomator) GetStayAwake() (int, error) {
+	return ua.Settings(SETTINGS_GLOBAL).GetInt("stay_on_while_plugged_in", STAY_AWAKE_OFF)
+}
+
+/*
+Set the stay awake mode while plugged in, see STAY_AWAKE_*
+*/
+func (ua *UIAutomator) SetStayAwake(mode int) error {
+	return ua.Settings(SETTINGS_GLOBAL).Put("stay_on_while_plugged_in", mode)
+}
+
+/*
+Get the location mode, see LOCATION_MODE_*
+*/
+func (ua *UIAutomator) GetLocationMode() (int, error) {
+	return ua.Settings(SETTINGS_SECURE).GetInt("location_mode", LOCATION_MODE_OFF)
+}
+
+/*
+Set the location mode, see LOCATION_MODE_*
+*/
+func (ua *UIAutomator) SetLocationMode(mode int) error {
+	return ua.Settings(SETTINGS_SECURE).Put("location_mode", mode)
+}
+
+/*
+Take a snapshot of the settings, restore them after the test
+*/
+func (ua *UIAutomator) SnapshotSettings(keys ...*SettingKey) (*SettingsSnapshot, error) {
+	snapshot := &SettingsSnapshot{ua: ua}
+
+	for _, key := range keys {
+		value, ok, err := ua.Settings(key.Namespace).Get(key.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot.keys = append(snapshot.keys, key)
+		if ok {
+			snapshot.values = append(snapshot.values, &value)
+		} else {
+			snapshot.values = append(snapshot.values, nil)
+		}
+	}
+
+	return snapshot, nil
+}
+
+/*
+Get the value in the snapshot, ok is false if the setting is not exists
+*/
+func (snapshot *SettingsSnapshot) Get(key *SettingKey) (value string, ok bool) {
+	for i, k := range snapshot.keys {
+		if *k == *key && snapshot.values[i] != nil {
+			return *snapshot.values[i], true
+		}
+	}
+
+	return "", false
+}
+
+/*
+Restore the settings, the settings not exists are deleted
+*/
+func (snapshot *SettingsSnapshot) Restore() error {
+	for i, key := range snapshot.keys {
+		settings := snapshot.ua.Settings(key.Namespace)
+
+		var err error
+		if snapshot.values[i] == nil {
+			err = settings.Delete(key.Name)
+		} else {
+			err = settings.Put(key.Name, *snapshot.values[i])
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,50 +1,249 @@
 package uiautomator
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
+// The extra time for atx-agent to respond after the command timeout
+const _SHELL_TIMEOUT_MARGIN = 5 * time.Second
+
+type (
+	ShellOptions struct {
+		Timeout      int  // Timeout(second) of the command
+		AllowFailure bool // Treat the non-zero exit code as data instead of error
+		Quote        bool // Quote every argument, the command is passed as argv
+	}
+
+	ShellResult struct {
+		ExitCode int
+		Output   string
+		Duration time.Duration
+	}
+
+	shellStreamReader struct {
+		reader   *bufio.Reader
+		body     io.Closer
+		cancel   context.CancelFunc
+		pid      int
+		finished int32
+	}
+)
+
+/*
+Run the shell command, returns the output.
+The arguments are joined by space as they are, so a whole command line, pipe or redirect can be passed in one element.
+The argument with spaces or special characters is split by the device shell, use RunShell with Quote to keep it
+*/
 func (ua *UIAutomator) Shell(command []string, timeout int) (output string, err error) {
-	requestURL := fmt.Sprintf("http://%s:%d/shell", ua.config.Host, ua.config.Port)
-	response, err := http.PostForm(
-		requestURL,
-		url.Values{
-			"command": {strings.Join(command, " ")},
-			"timeout": {strconv.Itoa(timeout)},
-		},
-	)
+	result, err := ua.RunShell(command, &ShellOptions{Timeout: timeout})
 	if err != nil {
 		return
 	}
 
-	if response.StatusCode != http.StatusOK {
-		err = boom(response)
-		return
+	output = result.Output
+	return
+}
+
+/*
+Run the shell command, returns the exit code, output and duration.
+The arguments are joined as Shell does, unless options.Quote is set, then every argument is quoted
+*/
+func (ua *UIAutomator) RunShell(command []string, options *ShellOptions) (*ShellResult, error) {
+	if options == nil {
+		options = &ShellOptions{}
+	}
+
+	quoted := command
+	if options.Quote {
+		quoted = make([]string, len(command))
+		for i, arg := range command {
+			quoted[i] = shellQuote(arg)
+		}
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%d/shell", ua.config.Host, ua.config.Port)
+	form := url.Values{
+		"command": {strings.Join(quoted, " ")},
+		"timeout": {strconv.Itoa(options.Timeout)},
 	}
 
+	request, err := http.NewRequest(http.MethodPost, requestURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
 	var ShellReturned struct {
 		ExitCode int    `json:"exitCode"`
 		Output   string `json:"output"`
 	}
+	transform := func(response *http.Response) error {
+		return json.NewDecoder(response.Body).Decode(&ShellReturned)
+	}
+
+	// The command may run longer than Config.Timeout and is not safe to re-run
+	timeout := time.Duration(ua.config.Timeout) * time.Second
+	if options.Timeout > 0 {
+		timeout = time.Duration(options.Timeout)*time.Second + _SHELL_TIMEOUT_MARGIN
+	}
+
+	started := time.Now()
+	if err := ua.executeOnce(request, timeout, transform); err != nil {
+		return nil, err
+	}
+
+	result := &ShellResult{
+		ExitCode: ShellReturned.ExitCode,
+		Output:   ShellReturned.Output,
+		Duration: time.Since(started),
+	}
+
+	if result.ExitCode != 0 && !options.AllowFailure {
+		return result, &UiaError{
+			Code:    result.ExitCode,
+			Message: fmt.Sprintf("Failed to execute command: %s", form.Get("command")),
+		}
+	}
+
+	return result, nil
+}
 
-	err = json.NewDecoder(response.Body).Decode(&ShellReturned)
+/*
+Run the command as argv, every argument is quoted, returns the output
+*/
+func (ua *UIAutomator) shellArgs(command []string, timeout int) (string, error) {
+	result, err := ua.RunShell(command, &ShellOptions{Timeout: timeout, Quote: true})
 	if err != nil {
-		return
+		return "", err
+	}
+
+	return result.Output, nil
+}
+
+/*
+Stream the output of the command, the stream is closed when the context is done
+*/
+func (ua *UIAutomator) shellStream(ctx context.Context, command string) (io.ReadCloser, error) {
+	requestURL := fmt.Sprintf(
+		"http://%s:%d/shell/stream?%s",
+		ua.config.Host,
+		ua.config.Port,
+		url.Values{"command": {command}}.Encode(),
+	)
+
+	request, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", "UIAUTOMATOR/"+VERSION)
+
+	// Without the client timeout, the stream lives until the context is done
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		return nil, boom(response)
+	}
+
+	return response.Body, nil
+}
+
+/*
+Run the long-running command, the output is delivered incrementally.
+The remote process is killed when the context is cancelled or the stream is closed
+*/
+func (ua *UIAutomator) ShellStream(ctx context.Context, command string) (io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Print the pid first, so the remote process can be killed later
+	body, err := ua.shellStream(ctx, "echo $$; exec sh -c "+shellQuote(command))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(body)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		cancel()
+		body.Close()
+		return nil, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		cancel()
+		body.Close()
+		return nil, fmt.Errorf("ShellStream: unexpected pid %q", line)
 	}
-	if ShellReturned.ExitCode != 0 {
-		err = &UiaError{
-			Code:    ShellReturned.ExitCode,
-			Message: fmt.Sprint("Failed to execute command: %s", command),
+
+	stream := &shellStreamReader{
+		reader: reader,
+		body:   body,
+		cancel: cancel,
+		pid:    pid,
+	}
+
+	go func() {
+		<-ctx.Done()
+		body.Close()
+
+		if atomic.LoadInt32(&stream.finished) == 0 {
+			ua.killProcess(pid)
 		}
+	}()
 
-		return
+	return stream, nil
+}
+
+func (stream *shellStreamReader) Read(p []byte) (int, error) {
+	n, err := stream.reader.Read(p)
+	if err == io.EOF {
+		atomic.StoreInt32(&stream.finished, 1)
 	}
 
-	output = ShellReturned.Output
-	return
+	return n, err
+}
+
+func (stream *shellStreamReader) Close() error {
+	stream.cancel()
+	return nil
+}
+
+/*
+Kill the process and its children
+*/
+func (ua *UIAutomator) killProcess(pid int) error {
+	_, err := ua.RunShell(
+		[]string{fmt.Sprintf("pkill -P %d; kill %d", pid, pid)},
+		&ShellOptions{Timeout: 5, AllowFailure: true},
+	)
+
+	return err
+}
+
+/*
+Quote the argument for the device shell, leave the safe argument as it is
+*/
+func shellQuote(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./-_", r))
+	}) == -1 {
+		return arg
+	}
+
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
 }
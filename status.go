@@ -0,0 +1,234 @@
+/**
+Device status, e.g. battery, network, storage and memory
+*/
+package uiautomator
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	_BATTERY_REGEXP = regexp.MustCompile(`(?m)^\s*([\w ]+):\s*(.+)$`)
+	_SSID_REGEXP    = regexp.MustCompile(`(?:SSID|extra): "([^"]+)"`)
+	_INET_REGEXP    = regexp.MustCompile(`inet\s+(\d+\.\d+\.\d+\.\d+)`)
+	_MEMINFO_REGEXP = regexp.MustCompile(`(?m)^(MemTotal|MemAvailable):\s+(\d+)\s*kB`)
+)
+
+type (
+	DeviceStatus struct {
+		Serial       string
+		Manufacturer string
+		Model        string
+
+		AndroidVersion string
+		SdkInt         int
+		ABI            string
+		AgentVersion   string
+
+		BatteryLevel       int     // Percent
+		BatteryTemperature float32 // Celsius
+		Charging           bool
+		Plugged            bool
+
+		WifiSSID  string
+		IPAddress string
+
+		FreeStorage     int64 // Bytes free of /data
+		TotalMemory     int64 // Bytes
+		AvailableMemory int64 // Bytes
+	}
+
+	batteryStatus struct {
+		level       int
+		temperature float32
+		charging    bool
+		plugged     bool
+	}
+)
+
+/*
+Get the device status, combines atx-agent info with dumpsys, df and meminfo
+*/
+func (ua *UIAutomator) GetDeviceStatus() (*DeviceStatus, error) {
+	var agentInfo struct {
+		Serial       string `json:"serial"`
+		AgentVersion string `json:"agentVersion"`
+	}
+	transform := func(response *http.Response) error {
+		return json.NewDecoder(response.Body).Decode(&agentInfo)
+	}
+
+	if err := ua.get(&RPCOptions{URL: "info"}, nil, transform); err != nil {
+		return nil, err
+	}
+
+	props, err := ua.GetProps()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &DeviceStatus{
+		Serial:         agentInfo.Serial,
+		AgentVersion:   agentInfo.AgentVersion,
+		Manufacturer:   props["ro.product.manufacturer"],
+		Model:          props["ro.product.model"],
+		AndroidVersion: props["ro.build.version.release"],
+		ABI:            props["ro.product.cpu.abi"],
+	}
+	status.SdkInt, _ = strconv.Atoi(props["ro.build.version.sdk"])
+
+	output, err := ua.Shell([]string{"dumpsys", "battery"}, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	battery := parseBattery(output)
+	status.BatteryLevel = battery.level
+	status.BatteryTemperature = battery.temperature
+	status.Charging = battery.charging
+	status.Plugged = battery.plugged
+
+	// The network is optional, e.g. the device is offline
+	if output, err := ua.Shell([]string{"dumpsys", "connectivity"}, 10); err == nil {
+		status.WifiSSID = parseSSID(output)
+	}
+
+	if output, err := ua.Shell([]string{"ip", "-f", "inet", "addr", "show", "wlan0"}, 5); err == nil {
+		if matched := _INET_REGEXP.FindStringSubmatch(output); matched != nil {
+			status.IPAddress = matched[1]
+		}
+	}
+
+	if output, err := ua.Shell([]string{"df", "/data"}, 10); err == nil {
+		status.FreeStorage = parseDiskFree(output)
+	}
+
+	output, err = ua.Shell([]string{"cat", "/proc/meminfo"}, 5)
+	if err != nil {
+		return nil, err
+	}
+	status.TotalMemory, status.AvailableMemory = parseMeminfo(output)
+
+	return status, nil
+}
+
+/*
+Parse the output of "dumpsys battery"
+*/
+func parseBattery(output string) *batteryStatus {
+	battery := &batteryStatus{}
+
+	for _, matched := range _BATTERY_REGEXP.FindAllStringSubmatch(output, -1) {
+		value := strings.TrimSpace(matched[2])
+
+		switch strings.TrimSpace(matched[1]) {
+		case "AC powered", "USB powered", "Wireless powered", "Dock powered":
+			battery.plugged = battery.plugged || value == "true"
+		case "status":
+			// BATTERY_STATUS_CHARGING
+			battery.charging = value == "2"
+		case "level":
+			battery.level, _ = strconv.Atoi(value)
+		case "temperature":
+			// Tenths of a degree Celsius
+			temperature, _ := strconv.Atoi(value)
+			battery.temperature = float32(temperature) / 10
+		}
+	}
+
+	return battery
+}
+
+/*
+Parse the Wi-Fi SSID from "dumpsys connectivity"
+*/
+func parseSSID(output string) string {
+	matched := _SSID_REGEXP.FindStringSubmatch(output)
+	if matched == nil || matched[1] == "<unknown ssid>" {
+		return ""
+	}
+
+	return matched[1]
+}
+
+/*
+Parse the available bytes from "df", supports both 1K-blocks and the human readable sizes
+*/
+func parseDiskFree(output string) int64 {
+	var header []string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if header == nil {
+			header = fields
+			continue
+		}
+
+		for i, name := range header {
+			if (name == "Available" || name == "Free") && i < len(fields) {
+				if header[1] == "1K-blocks" {
+					kb, _ := strconv.ParseInt(fields[i], 10, 64)
+					return kb * 1024
+				}
+				return parseSize(fields[i])
+			}
+		}
+	}
+
+	return 0
+}
+
+/*
+Parse the human readable size, e.g. "1.5G"
+*/
+func parseSize(size string) int64 {
+	units := map[byte]float64{
+		'K': 1 << 10,
+		'M': 1 << 20,
+		'G': 1 << 30,
+		'T': 1 << 40,
+	}
+
+	multiple := float64(1)
+	if len(size) > 0 {
+		if unit, ok := units[size[len(size)-1]]; ok {
+			multiple = unit
+			size = size[:len(size)-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil {
+		return 0
+	}
+
+	return int64(value * multiple)
+}
+
+/*
+Parse the total and available bytes from /proc/meminfo
+*/
+func parseMeminfo(output string) (total int64, available int64) {
+	for _, matched := range _MEMINFO_REGEXP.FindAllStringSubmatch(output, -1) {
+		kb, _ := strconv.ParseInt(matched[2], 10, 64)
+
+		switch matched[1] {
+		case "MemTotal":
+			total = kb * 1024
+		case "MemAvailable":
+			available = kb * 1024
+		}
+	}
+
+	return
+}
@@ -2,14 +2,17 @@ package uiautomator
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 )
 
@@ -36,8 +39,13 @@ type (
 	UIAutomator struct {
 		config     *Config
 		http       *http.Client
-		retryTimes int
-		size       *WindowSize
+		retryTimes int32 // Shared by the background workers, e.g. the app guard
+		display    *displayCache
+		guard      *AppGuard
+		animations *SettingsSnapshot
+		animErr    error // Failed to disable the animations by Config.DisableAnimations
+		human      *HumanGestureOptions
+		humanRand  *rand.Rand
 	}
 
 	Config struct {
@@ -50,6 +58,7 @@ type (
 		WaitForExistsMaxRetry    int     // Max retry times
 		WaitForDisappearDuration float32 // Unit second
 		WaitForDisappearMaxRetry int     // Max retry times
+		DisableAnimations        bool    // Disable the animations, restored on Close
 	}
 )
 
@@ -99,13 +108,32 @@ func New(config *Config) *UIAutomator {
 		config.WaitForDisappearMaxRetry = WAIT_FOR_DISAPPEAR_MAX_RETRY
 	}
 
-	return &UIAutomator{
+	ua := &UIAutomator{
 		config: config,
 		http: &http.Client{
 			Timeout: time.Duration(config.Timeout) * time.Second,
 		},
 		retryTimes: 0,
+		display:    &displayCache{},
 	}
+
+	if config.DisableAnimations {
+		// The device may be not ready yet, the error is kept for AnimationsError
+		ua.animErr = ua.DisableAnimations()
+	}
+
+	return ua
+}
+
+/*
+Release the client, stop the app guard and restore the animations
+*/
+func (ua *UIAutomator) Close() error {
+	if ua.guard != nil {
+		ua.guard.Stop()
+	}
+
+	return ua.RestoreAnimations()
 }
 
 func (ua UIAutomator) GetConfig() *Config {
@@ -145,7 +173,7 @@ func (ua *UIAutomator) caniRetry(err error) bool {
 		// Retry duration should not 0
 		ua.config.RetryDuration > 0 &&
 		// Retry time should be less than max auto retry times
-		ua.retryTimes < ua.config.AutoRetry
+		int(atomic.LoadInt32(&ua.retryTimes)) < ua.config.AutoRetry
 
 	if shouldRetry {
 		switch err := err.(type) {
@@ -164,16 +192,51 @@ func (ua *UIAutomator) caniRetry(err error) bool {
 	return false
 }
 
+/*
+Send the request once, without the client timeout and the retry of execute.
+For the request outliving Config.Timeout, or not safe to re-send, e.g. the file transfer
+and the shell command. The timeout 0 is unlimited
+*/
+func (ua *UIAutomator) executeOnce(request *http.Request, timeout time.Duration, transform func(*http.Response) error) error {
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(request.Context(), timeout)
+		defer cancel()
+		request = request.WithContext(ctx)
+	}
+	request.Header.Set("User-Agent", "UIAUTOMATOR/"+VERSION)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return boom(response)
+	}
+
+	return transform(response)
+}
+
 func (ua *UIAutomator) execute(request *http.Request, result interface{}, transform interface{}) error {
 	for {
-		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+		if request.Header.Get("Content-Type") == "" {
+			request.Header.Set("Content-Type", "application/json; charset=utf-8")
+		}
 		request.Header.Set("User-Agent", "UIAUTOMATOR/"+VERSION)
 
 		response, err := ua.http.Do(request)
 		if err != nil {
 			if ua.caniRetry(err) {
 				time.Sleep(time.Duration(ua.config.RetryDuration) * time.Second)
-				ua.retryTimes++
+				atomic.AddInt32(&ua.retryTimes, 1)
+
+				// Rewind the request body
+				if request.GetBody != nil {
+					if request.Body, err = request.GetBody(); err != nil {
+						return err
+					}
+				}
 				continue
 			}
 			return err
@@ -234,7 +297,7 @@ func (ua *UIAutomator) post(options *RPCOptions, result interface{}, transform i
 	}{
 		Jsonrpc: "2.0",
 		ID: func() string {
-			text := fmt.Sprintf("%s at %u", options.Method, time.Now().Unix())
+			text := fmt.Sprintf("%s at %d", options.Method, time.Now().Unix())
 			hasher := md5.New()
 			hasher.Write([]byte(text))
 			return hex.EncodeToString(hasher.Sum(nil))
@@ -0,0 +1,117 @@
+package uiautomator
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const _WEBSOCKET_GUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+/*
+A minimal websocket client, only sends text frames
+*/
+type websocketConn struct {
+	conn net.Conn
+}
+
+func (ua *UIAutomator) dialWebsocket(path string) (*websocketConn, error) {
+	address := net.JoinHostPort(ua.config.Host, strconv.Itoa(ua.config.Port))
+
+	conn, err := net.DialTimeout("tcp", address, time.Duration(ua.config.Timeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", address, path), nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Sec-WebSocket-Key", key)
+	request.Header.Set("Sec-WebSocket-Version", "13")
+	request.Header.Set("User-Agent", "UIAUTOMATOR/"+VERSION)
+
+	if err := request.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	hasher := sha1.New()
+	hasher.Write([]byte(key + _WEBSOCKET_GUID))
+	accept := base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != accept {
+		conn.Close()
+		return nil, fmt.Errorf("Websocket: failed to upgrade %s (%d)", path, response.StatusCode)
+	}
+
+	// The server messages are not used, drain them
+	go io.Copy(ioutil.Discard, reader)
+
+	return &websocketConn{conn: conn}, nil
+}
+
+func (ws *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	length := len(payload)
+
+	// The client frames are always masked
+	switch {
+	case length < 126:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := ws.conn.Write(frame)
+	return err
+}
+
+func (ws *websocketConn) WriteText(payload []byte) error {
+	return ws.writeFrame(0x1, payload)
+}
+
+func (ws *websocketConn) Close() error {
+	ws.writeFrame(0x8, nil)
+	return ws.conn.Close()
+}
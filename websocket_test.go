@@ -0,0 +1,78 @@
+package uiautomator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+func TestWebsocketWriteFrame(t *testing.T) {
+	cases := []struct {
+		name   string
+		length int
+		header int // Length of the header before the mask
+	}{
+		{"empty", 0, 2},
+		{"7-bit max", 125, 2},
+		{"16-bit min", 126, 4},
+		{"16-bit max", 0xFFFF, 4},
+		{"64-bit min", 0x10000, 10},
+	}
+
+	for _, c := range cases {
+		client, server := net.Pipe()
+		ws := &websocketConn{conn: client}
+
+		payload := bytes.Repeat([]byte("x"), c.length)
+		written := make(chan error, 1)
+		go func() {
+			written <- ws.WriteText(payload)
+			client.Close()
+		}()
+
+		frame, err := ioutil.ReadAll(server)
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		if err := <-written; err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+
+		if len(frame) != c.header+4+c.length {
+			t.Errorf("%s: expected frame length %d, got %d", c.name, c.header+4+c.length, len(frame))
+			continue
+		}
+
+		if frame[0] != 0x81 {
+			t.Errorf("%s: expected final text frame, got %#x", c.name, frame[0])
+		}
+
+		if frame[1]&0x80 == 0 {
+			t.Errorf("%s: expected masked frame", c.name)
+		}
+
+		var length int
+		switch c.header {
+		case 2:
+			length = int(frame[1] & 0x7F)
+		case 4:
+			length = int(binary.BigEndian.Uint16(frame[2:4]))
+		default:
+			length = int(binary.BigEndian.Uint64(frame[2:10]))
+		}
+		if length != c.length {
+			t.Errorf("%s: expected payload length %d, got %d", c.name, c.length, length)
+		}
+
+		mask := frame[c.header : c.header+4]
+		for i, b := range frame[c.header+4:] {
+			if b^mask[i%4] != 'x' {
+				t.Errorf("%s: payload byte %d is not unmasked", c.name, i)
+				break
+			}
+		}
+	}
+}